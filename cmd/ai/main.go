@@ -1,11 +1,14 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
 
 	"enum_tools/pkg/config"
 	"enum_tools/pkg/rag"
@@ -16,8 +19,16 @@ func main() {
 	configPath := flag.String("config", "config.yaml", "配置文件路径")
 	docsDir := flag.String("docs", "./docs", "文档目录路径")
 	skipLoad := flag.Bool("skip-load", false, "是否跳过加载文档")
+	retrieval := flag.String("retrieval", "", "检索方式：vector、keyword 或 hybrid，覆盖配置文件中的设置")
+	var filters stringList
+	flag.Var(&filters, "filter", "只检索元数据满足条件的分块，如 project=billing、doc_type=table，可重复指定，逗号分隔多个取值")
 	flag.Parse()
 
+	filter, err := rag.ParseFilter(filters...)
+	if err != nil {
+		log.Fatalf("解析过滤条件失败: %v", err)
+	}
+
 	// 加载配置
 	cfg, err := loadConfig(*configPath)
 	if err != nil {
@@ -28,6 +39,12 @@ func main() {
 	if *docsDir != "" {
 		cfg.Docs.Dir = *docsDir
 	}
+	if *retrieval != "" {
+		if !rag.ValidRetrievalMode(*retrieval) {
+			log.Fatalf("不支持的检索方式: %s", *retrieval)
+		}
+		cfg.Retrieval.Mode = *retrieval
+	}
 
 	// 创建RAG服务配置
 	ragConfig := rag.RAGConfig{
@@ -38,9 +55,35 @@ func main() {
 		EmbeddingModel: cfg.LLM.EmbeddingModel,
 		MaxTokens:      cfg.LLM.MaxTokens,
 		VectorStore: rag.VectorStoreConfig{
-			QdrantURL:      cfg.VectorStore.URL,
+			Type:           cfg.VectorStore.Type,
+			URL:            cfg.VectorStore.URL,
+			DSN:            cfg.VectorStore.DSN,
+			Driver:         cfg.VectorStore.Driver,
 			CollectionName: "default_collection",
 			PersistDir:     cfg.VectorStore.PersistDir,
+			Index:          cfg.VectorStore.Index,
+			HNSW: rag.HNSWConfig{
+				M:              cfg.VectorStore.HNSW.M,
+				EfConstruction: cfg.VectorStore.HNSW.EfConstruction,
+				EfSearch:       cfg.VectorStore.HNSW.EfSearch,
+			},
+		},
+		Retrieval: rag.RetrievalConfig{
+			Mode:            cfg.Retrieval.Mode,
+			TopK:            cfg.Retrieval.TopK,
+			Candidates:      cfg.Retrieval.Candidates,
+			VectorWeight:    cfg.Retrieval.VectorWeight,
+			KeywordWeight:   cfg.Retrieval.KeywordWeight,
+			RRFK:            cfg.Retrieval.RRFK,
+			MinScore:        cfg.Retrieval.MinScore,
+			KeywordMinScore: cfg.Retrieval.KeywordMinScore,
+			NoContext:       cfg.Retrieval.NoContext,
+		},
+		Rerank: rag.RerankConfig{
+			Type:       cfg.Rerank.Type,
+			Candidates: cfg.Rerank.Candidates,
+			TopK:       cfg.Rerank.TopK,
+			MinScore:   cfg.Rerank.MinScore,
 		},
 	}
 
@@ -51,22 +94,84 @@ func main() {
 		log.Fatalf("创建RAG服务失败: %v", err)
 	}
 
-	// 交互式问答循环
+	if !filter.Empty() {
+		log.Printf("只检索满足条件的分块: %s", filter)
+	}
+
+	// 交互式问答循环，回答逐字输出，回答过程中按 Ctrl+C 中断当前回答
+	scanner := bufio.NewScanner(os.Stdin)
 	for {
 		fmt.Print("请输入查询，exit退出: ")
-		query := ""
-		fmt.Scanln(&query)
+		if !scanner.Scan() {
+			break
+		}
+		query := strings.TrimSpace(scanner.Text())
+		if query == "" {
+			continue
+		}
 		if query == "exit" {
 			break
 		}
-		answer, err := service.Query(ctx, query)
+
+		queryCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		started := false
+		answer, err := service.QueryStream(queryCtx, query, func(token string) error {
+			if !started {
+				started = true
+				fmt.Print("回答: ")
+			}
+			fmt.Print(token)
+			return nil
+		}, rag.WithFilter(filter))
+		interrupted := queryCtx.Err() != nil
+		stop()
+		if started {
+			fmt.Println()
+		}
+		if interrupted {
+			fmt.Println("已中断回答")
+			continue
+		}
 		if err != nil {
 			log.Fatalf("查询失败: %v", err)
 		}
-		fmt.Println("回答:", answer)
+		printSources(answer)
 	}
 }
 
+// printSources 输出回答引用的来源
+func printSources(answer *rag.Answer) {
+	if len(answer.Sources) == 0 {
+		return
+	}
+	fmt.Println("来源:")
+	for _, source := range answer.Sources {
+		fmt.Printf("  [%d] %s（%s）\n", source.Index, source.Location(), sourceScore(source))
+		fmt.Printf("      %s\n", source.Excerpt)
+	}
+}
+
+// sourceScore 按得分类型标注来源的得分，融合和重排得分附带向量检索的相似度
+func sourceScore(source rag.Source) string {
+	var label string
+	switch source.ScoreKind {
+	case rag.ScoreCosine:
+		return fmt.Sprintf("相似度 %.3f", source.Score)
+	case rag.ScoreBM25:
+		return fmt.Sprintf("BM25 得分 %.3f", source.Score)
+	case rag.ScoreRRF:
+		label = fmt.Sprintf("融合得分 %.4f", source.Score)
+	case rag.ScoreRerank:
+		label = fmt.Sprintf("重排得分 %.3f", source.Score)
+	default:
+		return fmt.Sprintf("得分 %.3f", source.Score)
+	}
+	if source.VectorScore != 0 {
+		label += fmt.Sprintf("，相似度 %.3f", source.VectorScore)
+	}
+	return label
+}
+
 // loadConfig 加载配置文件，如果文件不存在则使用默认配置
 func loadConfig(configPath string) (*config.Config, error) {
 	// 尝试加载配置文件
@@ -97,12 +202,22 @@ func createRAGService(ctx context.Context, config rag.RAGConfig, skipLoad bool)
 		service.SetDocsProcessed(true)
 	} else {
 		log.Println("正在加载和处理文档...")
-		err = service.LoadAndProcessDocuments(ctx)
+		stats, err := service.LoadAndProcessDocuments(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("加载和处理文档失败: %w", err)
 		}
-		log.Println("文档加载和处理完成")
+		log.Printf("文档加载和处理完成：%s", stats)
 	}
 
 	return service, nil
 }
+
+// stringList 可重复指定的命令行参数
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, " ") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
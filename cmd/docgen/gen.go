@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"enum_tools/pkg/docgen"
+)
+
+// 代码生成子命令：docgen gen <target> [flags]
+func runGen(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: docgen gen <ts|go|sql> [flags]")
+	}
+
+	target := args[0]
+	fs := flag.NewFlagSet("gen "+target, flag.ExitOnError)
+	localPath := fs.String("localpath", ".", "本地项目路径")
+	outputPath := fs.String("output", "", "生成文件的输出目录")
+	configPath := fs.String("config", "", "docgen 配置文件路径")
+
+	cfg := docgen.DefaultConfig()
+	var layout, style, file, mode, dialect string
+	switch target {
+	case "ts":
+		fs.StringVar(&layout, "layout", "", "输出布局：package（每个包一个文件）或 single（单个文件）")
+		fs.StringVar(&style, "style", "", "枚举风格：enum（export enum）或 const（as const 对象）")
+	case "go":
+		// go:generate 运行时工作目录为包目录，GOFILE 为触发生成的源文件
+		fs.StringVar(&file, "file", os.Getenv("GOFILE"), "只处理指定的源文件，默认读取 go:generate 设置的 GOFILE")
+	case "sql":
+		fs.StringVar(&mode, "mode", "", "约束生成方式：check（CHECK 约束）或 enum（CREATE TYPE ... AS ENUM）")
+		fs.StringVar(&dialect, "dialect", "", "数据库方言，目前只支持 postgres")
+	default:
+		return fmt.Errorf("不支持的生成目标: %s", target)
+	}
+	fs.Parse(args[1:])
+
+	if *configPath != "" {
+		var err error
+		cfg, err = docgen.LoadConfig(*configPath)
+		if err != nil {
+			return fmt.Errorf("加载配置失败: %w", err)
+		}
+	}
+	if layout != "" {
+		cfg.Gen.TS.Layout = layout
+	}
+	if style != "" {
+		cfg.Gen.TS.Style = style
+	}
+	if mode != "" {
+		cfg.Gen.SQL.Mode = mode
+	}
+	if dialect != "" {
+		cfg.Gen.SQL.Dialect = dialect
+	}
+	if topLevel, err := docgen.GitTopLevel(*localPath); err == nil {
+		cfg.RepoRoot = topLevel
+	}
+
+	parser := docgen.NewParserWithConfig(cfg)
+
+	var files map[string][]byte
+	var stale []string
+	var err error
+	switch target {
+	case "go":
+		// 生成文件写在源文件旁，不使用输出目录
+		if file != "" && !filepath.IsAbs(file) {
+			file = filepath.Join(*localPath, file)
+		}
+		*outputPath = ""
+		files, stale, err = parser.GenerateGo(*localPath, file)
+	case "ts":
+		if _, err := parser.ParseEnums(*localPath); err != nil {
+			return fmt.Errorf("解析枚举失败: %w", err)
+		}
+		if *outputPath == "" {
+			*outputPath = "web/src/enums"
+		}
+		files, err = parser.GenerateTS()
+	case "sql":
+		if _, err := parser.ParseEnums(*localPath); err != nil {
+			return fmt.Errorf("解析枚举失败: %w", err)
+		}
+		if _, err := parser.ParseDBComments(*localPath); err != nil {
+			return fmt.Errorf("解析数据库注释失败: %w", err)
+		}
+		if *outputPath == "" {
+			*outputPath = "migrations"
+		}
+		files, err = parser.GenerateSQL()
+	}
+	if err != nil {
+		return fmt.Errorf("生成 %s 代码失败: %w", target, err)
+	}
+
+	written, err := docgen.WriteGenerated(*outputPath, files)
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Fprintf(os.Stdout, "已生成 %s\n", path)
+	}
+	// @ai 块删除或改名后留下的生成文件
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("删除过期的生成文件失败: %w", err)
+		}
+		fmt.Fprintf(os.Stdout, "已删除 %s\n", path)
+	}
+	return nil
+}
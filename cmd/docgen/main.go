@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"enum_tools/pkg/docgen"
 )
@@ -13,17 +18,73 @@ import (
 var defaultGitPath string
 
 func main() {
+	// 代码生成子命令
+	if len(os.Args) > 1 && os.Args[1] == "gen" {
+		if err := runGen(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	outputPath := "./docs"
+	configPath := ""
+	remoteURL := ""
+	commit := ""
+	configTypes := ""
+	formats := "markdown"
+	manifestPath := ""
+	watch := false
+	watchInterval := docgen.DefaultWatchInterval
 	flag.StringVar(&outputPath, "output", "docs", "输出文档目录")
 	flag.StringVar(&defaultGitPath, "localpath", "", "本地项目路径")
+	flag.StringVar(&configPath, "config", "", "docgen 配置文件路径")
+	flag.StringVar(&remoteURL, "remote-url", "", "源码链接模板，如 https://github.com/org/repo/blob/{commit}/{path}#L{start}-L{end}")
+	flag.StringVar(&commit, "commit", "", "源码链接使用的提交，默认读取 git HEAD")
+	flag.StringVar(&configTypes, "config-types", "", "需要生成配置说明的结构体，多个用逗号分隔，如 config.Config")
+	flag.StringVar(&formats, "format", "markdown", "输出格式，多个用逗号分隔：markdown、csv、xlsx、html")
+	flag.StringVar(&manifestPath, "manifest", "", "多仓库清单文件，如 docgen.yaml，设置后忽略 --localpath 和 --output")
+	flag.BoolVar(&watch, "watch", false, "持续监听文件变化并重新生成文档")
+	flag.DurationVar(&watchInterval, "watch-interval", docgen.DefaultWatchInterval, "监听模式下的轮询间隔")
 	flag.Parse()
 
-	if err := run(outputPath); err != nil {
+	cfg := docgen.DefaultConfig()
+	if configPath != "" {
+		var err error
+		cfg, err = docgen.LoadConfig(configPath)
+		if err != nil {
+			log.Fatalf("加载配置失败: %v", err)
+		}
+	}
+
+	// 命令行参数覆盖配置文件
+	if remoteURL != "" {
+		cfg.Permalink.URLPattern = remoteURL
+	}
+	if commit != "" {
+		cfg.Permalink.Commit = commit
+	}
+	for _, typeName := range strings.Split(configTypes, ",") {
+		if typeName = strings.TrimSpace(typeName); typeName != "" {
+			cfg.ConfigDoc.Types = append(cfg.ConfigDoc.Types, typeName)
+		}
+	}
+
+	if manifestPath != "" {
+		if watch {
+			log.Fatal("--watch 暂不支持与 --manifest 同时使用")
+		}
+		if err := runManifest(manifestPath, cfg); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := run(outputPath, strings.Split(formats, ","), cfg, watch, watchInterval); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func run(outputPath string) error {
+func run(outputPath string, formats []string, cfg *docgen.Config, watch bool, watchInterval time.Duration) error {
 	// 确保输出目录存在
 	if err := os.MkdirAll(outputPath, 0755); err != nil {
 		return fmt.Errorf("创建输出目录失败: %w", err)
@@ -32,29 +93,108 @@ func run(outputPath string) error {
 	// 获取项目名称
 	projectName := filepath.Base(defaultGitPath)
 
-	parser := docgen.NewParser()
+	// 以 git 仓库根目录计算源码路径，非 git 目录时退化为解析目录
+	if topLevel, err := docgen.GitTopLevel(defaultGitPath); err == nil {
+		cfg.RepoRoot = topLevel
+	}
+	if cfg.Permalink.URLPattern != "" && cfg.Permalink.Commit == "" {
+		commit, err := docgen.GitCommit(defaultGitPath, "")
+		if err != nil {
+			return fmt.Errorf("获取当前提交失败: %w", err)
+		}
+		cfg.Permalink.Commit = commit
+	}
 
-	// 解析枚举
-	_, err := parser.ParseEnums(defaultGitPath)
-	if err != nil {
-		return fmt.Errorf("解析枚举失败: %w", err)
+	parser := docgen.NewParserWithConfig(cfg)
+	if err := parser.RunExtractors(defaultGitPath, nil); err != nil {
+		return err
 	}
 
-	// 解析数据库注释
-	_, err = parser.ParseDBComments(defaultGitPath)
-	if err != nil {
-		return fmt.Errorf("解析数据库注释失败: %w", err)
+	for _, format := range formats {
+		if err := writeOutput(parser, outputPath, projectName, strings.TrimSpace(format)); err != nil {
+			return err
+		}
+	}
+	if !watch {
+		return nil
 	}
 
-	// 生成 Markdown 文档
-	mdContent := parser.ToMarkdown()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.Printf("正在监听 %s 的文件变化，按 Ctrl+C 退出", defaultGitPath)
 
-	// 使用项目名称作为文件名
-	mdFileName := fmt.Sprintf("%s/knowledge_%s.md", outputPath, projectName)
-	err = os.WriteFile(mdFileName, []byte(mdContent), 0644)
-	if err != nil {
-		log.Fatalf("写入文档失败: %v", err)
+	before := parser.Fingerprints()
+	opts := docgen.WatchOptions{
+		Extensions: docgen.ExtractorExtensions(nil),
+		Interval:   watchInterval,
 	}
+	return parser.Watch(ctx, defaultGitPath, opts, func(changed []string) {
+		start := time.Now()
+		// 重新执行全部提取器，只有变化的 Go 文件需要重新解析
+		parser.Invalidate(changed)
+		parser.Reset()
+		if err := parser.RunExtractors(defaultGitPath, nil); err != nil {
+			log.Printf("重新解析失败: %v", err)
+			return
+		}
+
+		after := parser.Fingerprints()
+		changes := docgen.DiffFingerprints(before, after)
+		if changes.Empty() {
+			log.Printf("%d 个文件有变化，文档内容不变", len(changed))
+			return
+		}
+		for _, format := range formats {
+			if err := writeOutput(parser, outputPath, projectName, strings.TrimSpace(format)); err != nil {
+				log.Printf("写入文档失败: %v", err)
+				return
+			}
+		}
+		before = after
+		log.Printf("已重新生成文档（%d 个文件有变化，耗时 %s）：%s",
+			len(changed), time.Since(start).Round(time.Millisecond), changes)
+	})
+}
 
+// 按格式写出文档：Markdown 知识库文档、CSV / XLSX 数据字典或 HTML 站点
+func writeOutput(parser *docgen.Parser, outputPath, projectName, format string) error {
+	switch format {
+	case "markdown", "md":
+		// 使用项目名称作为文件名
+		mdFileName := fmt.Sprintf("%s/knowledge_%s.md", outputPath, projectName)
+		if err := docgen.WriteFileAtomic(mdFileName, []byte(parser.ToMarkdown()), 0644); err != nil {
+			return fmt.Errorf("写入文档失败: %w", err)
+		}
+	case "csv":
+		files, err := parser.ExportCSV()
+		if err != nil {
+			return fmt.Errorf("导出 CSV 失败: %w", err)
+		}
+		for name, content := range files {
+			fileName := fmt.Sprintf("%s/dictionary_%s_%s", outputPath, projectName, name)
+			if err := docgen.WriteFileAtomic(fileName, content, 0644); err != nil {
+				return fmt.Errorf("写入 CSV 失败: %w", err)
+			}
+		}
+	case "xlsx":
+		content, err := parser.ExportXLSX()
+		if err != nil {
+			return fmt.Errorf("导出 XLSX 失败: %w", err)
+		}
+		fileName := fmt.Sprintf("%s/dictionary_%s.xlsx", outputPath, projectName)
+		if err := docgen.WriteFileAtomic(fileName, content, 0644); err != nil {
+			return fmt.Errorf("写入 XLSX 失败: %w", err)
+		}
+	case "html":
+		files, err := parser.GenerateSite(projectName)
+		if err != nil {
+			return fmt.Errorf("生成 HTML 站点失败: %w", err)
+		}
+		if _, err := docgen.WriteGenerated(filepath.Join(outputPath, "site_"+projectName), files); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("不支持的输出格式: %s", format)
+	}
 	return nil
 }
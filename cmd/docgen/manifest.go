@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"enum_tools/pkg/docgen"
+)
+
+// 按清单并行处理多个项目，分别输出每个项目的文档，再合并输出一份总目录
+func runManifest(manifestPath string, base *docgen.Config) error {
+	manifest, err := docgen.LoadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(manifest.Output, 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	parsers := make([]*docgen.Parser, len(manifest.Projects))
+	errs := make([]error, len(manifest.Projects))
+	sem := make(chan struct{}, manifest.Parallel)
+	var wg sync.WaitGroup
+	for i, project := range manifest.Projects {
+		wg.Add(1)
+		go func(i int, project docgen.ProjectConfig) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			parser, err := runProject(manifest, project, base)
+			if err != nil {
+				errs[i] = fmt.Errorf("项目 %s: %w", project.Name, err)
+				log.Printf("项目 %s 生成失败: %v", project.Name, err)
+				return
+			}
+			parsers[i] = parser
+			log.Printf("项目 %s 生成完成", project.Name)
+		}(i, project)
+	}
+	wg.Wait()
+
+	// 按清单顺序合并，保证输出稳定
+	combined := docgen.NewParserWithConfig(base)
+	for i, parser := range parsers {
+		if parser != nil {
+			combined.Merge(manifest.Projects[i].Name, parser)
+		}
+	}
+	for _, format := range manifest.Formats {
+		if err := writeOutput(combined, manifest.Output, manifest.Combined, format); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// 解析单个项目并写出项目自己的文档
+func runProject(manifest *docgen.Manifest, project docgen.ProjectConfig, base *docgen.Config) (*docgen.Parser, error) {
+	cfg := *base
+	cfg.Include = append(append([]string(nil), base.Include...), project.Include...)
+	cfg.Exclude = append(append([]string(nil), base.Exclude...), project.Exclude...)
+	if project.Dialect != "" {
+		cfg.Gen.SQL.Dialect = project.Dialect
+	}
+	if project.RemoteURL != "" {
+		cfg.Permalink.URLPattern = project.RemoteURL
+	}
+	cfg.Permalink.Commit = ""
+
+	// 指定版本时从 git 导出源码，否则直接解析工作区
+	rootPath := project.Path
+	if project.Ref != "" {
+		dir, cleanup, err := docgen.ExportGitRef(project.Path, project.Ref)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		rootPath = dir
+		cfg.RepoRoot = dir
+	} else if topLevel, err := docgen.GitTopLevel(project.Path); err == nil {
+		cfg.RepoRoot = topLevel
+	} else {
+		cfg.RepoRoot = ""
+	}
+	if cfg.Permalink.URLPattern != "" {
+		commit, err := docgen.GitCommit(project.Path, project.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("获取提交失败: %w", err)
+		}
+		cfg.Permalink.Commit = commit
+	}
+
+	parser := docgen.NewParserWithConfig(&cfg)
+	if err := parser.RunExtractors(rootPath, project.Extractors); err != nil {
+		return nil, err
+	}
+
+	outputPath := filepath.Join(manifest.Output, project.Output)
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		return nil, fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	for _, format := range manifest.Formats {
+		if err := writeOutput(parser, outputPath, project.Output, format); err != nil {
+			return nil, err
+		}
+	}
+	return parser, nil
+}
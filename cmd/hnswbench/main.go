@@ -0,0 +1,128 @@
+// hnswbench 对比 HNSW 近似检索与暴力检索的召回率和延迟，用于选择 m、ef_construction、ef_search
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"enum_tools/pkg/rag"
+)
+
+func main() {
+	n := flag.Int("n", 10000, "向量数量")
+	dim := flag.Int("dim", 256, "向量维度")
+	queries := flag.Int("queries", 200, "查询数量")
+	k := flag.Int("k", 10, "每次查询返回的数量")
+	m := flag.Int("m", 16, "HNSW 每个节点的最大连接数")
+	efConstruction := flag.Int("ef-construction", 200, "HNSW 构建时的候选集大小")
+	efList := flag.String("ef", "16,32,64,128,256", "查询时的候选集大小，逗号分隔")
+	clusters := flag.Int("clusters", 50, "生成数据的簇数量，模拟文档分块的主题分布")
+	spread := flag.Float64("spread", 0.5, "簇内向量相对簇中心的偏离程度")
+	seed := flag.Int64("seed", 42, "随机数种子")
+	flag.Parse()
+
+	var efs []int
+	for _, s := range strings.Split(*efList, ",") {
+		ef, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || ef <= 0 {
+			log.Fatalf("无效的 ef: %q", s)
+		}
+		efs = append(efs, ef)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	centers := make([][]float32, *clusters)
+	for i := range centers {
+		centers[i] = randomVector(rng, *dim, nil, 0)
+	}
+	vectors := make([][]float32, *n)
+	for i := range vectors {
+		vectors[i] = randomVector(rng, *dim, centers[rng.Intn(*clusters)], *spread)
+	}
+	queryVectors := make([][]float32, *queries)
+	for i := range queryVectors {
+		queryVectors[i] = randomVector(rng, *dim, centers[rng.Intn(*clusters)], *spread)
+	}
+	fmt.Printf("数据：%d 个向量，%d 维，%d 个簇；%d 次查询，k=%d\n", *n, *dim, *clusters, *queries, *k)
+
+	start := time.Now()
+	index := rag.NewHNSW(rag.HNSWConfig{M: *m, EfConstruction: *efConstruction})
+	for i, v := range vectors {
+		index.Add(strconv.Itoa(i), v)
+	}
+	fmt.Printf("构建索引：m=%d ef_construction=%d，耗时 %s\n", *m, *efConstruction, time.Since(start).Round(time.Millisecond))
+
+	// 暴力检索作为基准
+	truth := make([]map[string]bool, len(queryVectors))
+	start = time.Now()
+	for i, q := range queryVectors {
+		truth[i] = exactSearch(vectors, q, *k)
+	}
+	exactLatency := time.Since(start) / time.Duration(len(queryVectors))
+	fmt.Printf("暴力检索：平均延迟 %s\n\n", exactLatency)
+
+	fmt.Printf("%-8s %-12s %-14s %s\n", "ef", "recall@"+strconv.Itoa(*k), "平均延迟", "加速比")
+	for _, ef := range efs {
+		hits := 0
+		start := time.Now()
+		for i, q := range queryVectors {
+			for _, r := range index.Search(q, *k, ef) {
+				if truth[i][r.ID] {
+					hits++
+				}
+			}
+		}
+		latency := time.Since(start) / time.Duration(len(queryVectors))
+		recall := float64(hits) / float64(len(queryVectors)**k)
+		fmt.Printf("%-8d %-12.4f %-14s %.1fx\n", ef, recall, latency, float64(exactLatency)/float64(latency))
+	}
+}
+
+// 生成归一化的随机向量，center 不为空时在其附近扰动，扰动向量的模长约为 spread
+func randomVector(rng *rand.Rand, dim int, center []float32, spread float64) []float32 {
+	v := make([]float32, dim)
+	var sum float64
+	for i := range v {
+		x := rng.NormFloat64()
+		if center != nil {
+			x = float64(center[i]) + x*spread/math.Sqrt(float64(dim))
+		}
+		v[i] = float32(x)
+		sum += x * x
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// 精确计算余弦相似度最高的 k 个向量
+func exactSearch(vectors [][]float32, q []float32, k int) map[string]bool {
+	type scored struct {
+		id    int
+		score float32
+	}
+	all := make([]scored, len(vectors))
+	for i, v := range vectors {
+		var s float32
+		for j := range v {
+			s += v[j] * q[j]
+		}
+		all[i] = scored{i, s}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].score > all[j].score })
+
+	ids := make(map[string]bool, k)
+	for _, s := range all[:min(k, len(all))] {
+		ids[strconv.Itoa(s.id)] = true
+	}
+	return ids
+}
@@ -0,0 +1,60 @@
+// vectorcheck 对向量存储后端运行契约检查，默认检查本地存储，不需要任何外部服务
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"enum_tools/pkg/rag"
+	"enum_tools/pkg/rag/backendtest"
+)
+
+func main() {
+	storeType := flag.String("type", rag.VectorStoreLocal, "向量存储类型：local、qdrant、chroma 或 pgvector")
+	url := flag.String("url", "", "Qdrant 或 Chroma 服务地址，默认使用各自的本地地址")
+	dsn := flag.String("dsn", "", "pgvector 的数据库连接串")
+	driver := flag.String("driver", "", "pgvector 使用的数据库驱动，默认为 pgx")
+	index := flag.String("index", rag.LocalIndexFlat, "本地存储的检索方式：flat 或 hnsw")
+	collection := flag.String("collection", "docgen_contract_check", "检查使用的集合，会被反复删除重建")
+	flag.Parse()
+
+	persistDir, err := os.MkdirTemp("", "vectorcheck-")
+	if err != nil {
+		log.Fatalf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(persistDir)
+
+	backend, err := rag.NewBackend(rag.VectorStoreConfig{
+		Type:           *storeType,
+		URL:            *url,
+		DSN:            *dsn,
+		Driver:         *driver,
+		CollectionName: *collection,
+		PersistDir:     persistDir,
+		Index:          *index,
+	})
+	if err != nil {
+		log.Fatalf("创建向量存储后端失败: %v", err)
+	}
+	defer backend.Close()
+
+	failed := 0
+	for _, r := range backendtest.Run(context.Background(), backend) {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("✗ %s: %v\n", r.Name, r.Err)
+		} else {
+			fmt.Printf("✓ %s\n", r.Name)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%s 后端有 %d 项检查未通过\n", *storeType, failed)
+		backend.Close()
+		os.RemoveAll(persistDir)
+		os.Exit(1)
+	}
+	fmt.Printf("%s 后端通过全部检查\n", *storeType)
+}
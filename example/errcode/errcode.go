@@ -0,0 +1,17 @@
+package errcode
+
+// Error 带错误码的业务错误
+type Error struct {
+	Code       int
+	Message    string
+	HTTPStatus int
+}
+
+// New 注册一个错误码
+func New(code int, message string, httpStatus int) *Error {
+	return &Error{Code: code, Message: message, HTTPStatus: httpStatus}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
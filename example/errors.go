@@ -0,0 +1,20 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+
+	"enum_tools/example/errcode"
+)
+
+// 订单相关错误码
+const (
+	CodeOrderNotFound = 40013 // 订单不存在
+	CodeOrderClosed   = 40014 // 订单已关闭
+)
+
+var (
+	ErrOrderNotFound = errcode.New(CodeOrderNotFound, "订单不存在", http.StatusNotFound)
+	ErrMailQuota     = errcode.New(42901, "邮件发送频率超限", http.StatusTooManyRequests)
+	ErrInvalidMail   = errors.New("邮件地址无效")
+)
@@ -0,0 +1,14 @@
+package test
+
+// OrderStatus 订单状态
+type OrderStatus string
+
+// @ai 订单状态
+// @ai:column order_details.order_status
+const (
+	OrderStatusInit       OrderStatus = "init"       // 初始化
+	OrderStatusPending    OrderStatus = "pending"    // 待处理
+	OrderStatusProcessing OrderStatus = "processing" // 处理中
+	OrderStatusCompleted  OrderStatus = "completed"  // 已完成
+	OrderStatusCancelled  OrderStatus = "cancelled"  // 已取消
+)
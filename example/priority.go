@@ -0,0 +1,14 @@
+package test
+
+//go:generate go run enum_tools/cmd/docgen gen go
+
+// MailPriority 邮件优先级
+type MailPriority int
+
+// @ai 邮件优先级
+const (
+	MailPriorityLow    MailPriority = iota + 1 // 低
+	MailPriorityNormal                         // 普通
+	MailPriorityHigh                           // 高
+	MailPriorityUrgent                         // 紧急
+)
@@ -0,0 +1,93 @@
+// Code generated by docgen. DO NOT EDIT.
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// _MailPriorityValues MailPriority 的全部取值，按声明顺序
+var _MailPriorityValues = []MailPriority{
+	MailPriorityLow,
+	MailPriorityNormal,
+	MailPriorityHigh,
+	MailPriorityUrgent,
+}
+
+// _MailPriorityNames MailPriority 的常量名
+var _MailPriorityNames = map[MailPriority]string{
+	MailPriorityLow:    "MailPriorityLow",
+	MailPriorityNormal: "MailPriorityNormal",
+	MailPriorityHigh:   "MailPriorityHigh",
+	MailPriorityUrgent: "MailPriorityUrgent",
+}
+
+// _MailPriorityLabels MailPriority 的显示名称
+var _MailPriorityLabels = map[MailPriority]string{
+	MailPriorityLow:    "低",
+	MailPriorityNormal: "普通",
+	MailPriorityHigh:   "高",
+	MailPriorityUrgent: "紧急",
+}
+
+// MailPriorityValues 返回邮件优先级的全部取值
+func MailPriorityValues() []MailPriority {
+	return append([]MailPriority(nil), _MailPriorityValues...)
+}
+
+// String 返回常量名
+func (v MailPriority) String() string {
+	if name, ok := _MailPriorityNames[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("MailPriority(%v)", int(v))
+}
+
+// Label 返回显示名称，取自常量注释
+func (v MailPriority) Label() string {
+	if label, ok := _MailPriorityLabels[v]; ok {
+		return label
+	}
+	return fmt.Sprintf("MailPriority(%v)", int(v))
+}
+
+// IsValid 判断是否为已定义的取值
+func (v MailPriority) IsValid() bool {
+	_, ok := _MailPriorityNames[v]
+	return ok
+}
+
+// ParseMailPriority 根据常量名或显示名称解析取值
+func ParseMailPriority(s string) (MailPriority, error) {
+	for _, v := range _MailPriorityValues {
+		if _MailPriorityNames[v] == s {
+			return v, nil
+		}
+	}
+	for _, v := range _MailPriorityValues {
+		if _MailPriorityLabels[v] == s {
+			return v, nil
+		}
+	}
+	var zero MailPriority
+	return zero, fmt.Errorf("无效的 MailPriority: %q", s)
+}
+
+// MarshalJSON 按底层值序列化
+func (v MailPriority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(v))
+}
+
+// UnmarshalJSON 按底层值反序列化，并校验取值是否已定义
+func (v *MailPriority) UnmarshalJSON(data []byte) error {
+	var raw int
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("解析 MailPriority 失败: %w", err)
+	}
+	if !MailPriority(raw).IsValid() {
+		return fmt.Errorf("无效的 MailPriority: %v", raw)
+	}
+	*v = MailPriority(raw)
+	return nil
+}
@@ -0,0 +1,19 @@
+package test
+
+import "net/http"
+
+// RegisterRoutes 注册邮件相关接口
+func RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/mails/{id}", GetMail)
+	mux.HandleFunc("POST /api/mails", CreateMail)
+}
+
+// GetMail 查询邮件发送状态
+func GetMail(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// CreateMail 创建并发送邮件
+func CreateMail(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusCreated)
+}
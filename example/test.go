@@ -10,6 +10,32 @@ const (
 	MailStatusFailed    = 4 // 发送失败
 )
 
+type Mail struct {
+	To     string
+	Status int
+}
+
 func Test() {
 	fmt.Println("test")
 }
+
+// SendMail 发送邮件并更新状态
+func SendMail(m *Mail) {
+	m.Status = MailStatusPending
+	if m.To == "" {
+		m.Status = MailStatusFailed
+		return
+	}
+	m.Status = MailStatusCompleted
+}
+
+// DescribeMail 返回邮件状态的文字说明
+func DescribeMail(m *Mail) string {
+	switch m.Status {
+	case MailStatusPending:
+		return "待发送"
+	case MailStatusFailed:
+		return "发送失败"
+	}
+	return "未知"
+}
@@ -8,9 +8,12 @@ import (
 )
 
 // Config 应用配置结构
+// @ai:config
 type Config struct {
 	LLM         LLMConfig         `yaml:"llm"`
 	VectorStore VectorStoreConfig `yaml:"vector_store"`
+	Retrieval   RetrievalConfig   `yaml:"retrieval"`
+	Rerank      RerankConfig      `yaml:"rerank"`
 	Docs        DocsConfig        `yaml:"docs"`
 	API         APIConfig         `yaml:"api"`
 }
@@ -24,9 +27,41 @@ type LLMConfig struct {
 
 // VectorStoreConfig 向量存储配置
 type VectorStoreConfig struct {
-	Type       string `yaml:"type"`        // 向量存储类型
-	URL        string `yaml:"url"`         // 向量存储服务器地址
-	PersistDir string `yaml:"persist_dir"` // 持久化目录
+	Type       string     `yaml:"type"`        // 向量存储类型：qdrant、chroma、pgvector 或 local
+	URL        string     `yaml:"url"`         // Qdrant 或 Chroma 服务器地址
+	DSN        string     `yaml:"dsn"`         // pgvector 的数据库连接串
+	Driver     string     `yaml:"driver"`      // pgvector 使用的数据库驱动，默认为 pgx
+	PersistDir string     `yaml:"persist_dir"` // 持久化目录
+	Index      string     `yaml:"index"`       // local 类型的检索方式：flat（精确）或 hnsw（近似）
+	HNSW       HNSWConfig `yaml:"hnsw"`        // index 为 hnsw 时的索引参数
+}
+
+// HNSWConfig HNSW 索引参数，未设置的项使用默认值
+type HNSWConfig struct {
+	M              int `yaml:"m"`               // 每个节点的最大连接数
+	EfConstruction int `yaml:"ef_construction"` // 构建时的候选集大小
+	EfSearch       int `yaml:"ef_search"`       // 查询时的候选集大小
+}
+
+// RetrievalConfig 检索配置
+type RetrievalConfig struct {
+	Mode            string  `yaml:"mode"`              // 检索方式：vector、keyword 或 hybrid
+	TopK            int     `yaml:"top_k"`             // 返回的分块数
+	Candidates      int     `yaml:"candidates"`        // 混合检索时每路召回的候选数
+	VectorWeight    float64 `yaml:"vector_weight"`     // 融合时向量检索的权重
+	KeywordWeight   float64 `yaml:"keyword_weight"`    // 融合时关键词检索的权重
+	RRFK            int     `yaml:"rrf_k"`             // 倒数排名融合的平滑常数
+	MinScore        float64 `yaml:"min_score"`         // 向量检索的余弦相似度下限，0 表示不限制
+	KeywordMinScore float64 `yaml:"keyword_min_score"` // 关键词检索的 BM25 得分下限，keyword 和 hybrid 方式都生效，0 表示不限制，此时混合检索要求至少一个向量结果通过 min_score
+	NoContext       string  `yaml:"no_context"`        // 没有相关分块时的处理方式：refuse 或 warn
+}
+
+// RerankConfig 重排配置
+type RerankConfig struct {
+	Type       string  `yaml:"type"`       // 重排方式：none、lexical、llm_pointwise 或 llm_listwise
+	Candidates int     `yaml:"candidates"` // 参与重排的候选分块数
+	TopK       int     `yaml:"top_k"`      // 重排后保留的分块数
+	MinScore   float64 `yaml:"min_score"`  // 重排得分下限，范围为 0 到 1
 }
 
 // DocsConfig 文档配置
@@ -79,6 +114,21 @@ func GetDefaultConfig() *Config {
 			Type:       "qdrant",
 			URL:        "http://localhost:6333",
 			PersistDir: "./data/qdrant",
+			Index:      "flat",
+		},
+		Retrieval: RetrievalConfig{
+			Mode:          "hybrid",
+			TopK:          5,
+			Candidates:    20,
+			VectorWeight:  1,
+			KeywordWeight: 1,
+			RRFK:          60,
+			NoContext:     "refuse",
+		},
+		Rerank: RerankConfig{
+			Type:       "none",
+			Candidates: 30,
+			TopK:       5,
 		},
 		Docs: DocsConfig{
 			Dir: "./docs",
@@ -0,0 +1,116 @@
+package docgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 分类规则的匹配范围
+const (
+	CategoryMatchName        = "name"        // 枚举组名称，含类型名和组注释
+	CategoryMatchDescription = "description" // 枚举组描述
+	CategoryMatchItems       = "items"       // 枚举项常量名
+)
+
+// 内置分类规则，同时匹配英文类型名和中文注释
+var builtinCategoryRules = []CategoryRule{
+	{Category: "状态", Pattern: `(?i)status|state|状态`},
+	{Category: "类型", Pattern: `(?i)type|kind|类型|种类`},
+	{Category: "标志", Pattern: `(?i)flag|标志|标记|开关`},
+	{Category: "模式", Pattern: `(?i)mode|模式|方式`},
+	{Category: "级别", Pattern: `(?i)level|priority|级别|等级|优先级`},
+}
+
+type categoryRule struct {
+	category string
+	pattern  *regexp.Regexp
+	match    []string
+}
+
+// 编译分类规则，自定义规则在前，内置规则在后；返回的错误包含所有无效规则，
+// 无效规则被跳过，其余规则仍然可用
+func compileCategoryRules(cfg CategoryConfig) ([]categoryRule, error) {
+	rules := cfg.Rules
+	if cfg.Builtin == nil || *cfg.Builtin {
+		rules = append(append([]CategoryRule(nil), rules...), builtinCategoryRules...)
+	}
+
+	var compiled []categoryRule
+	var errs []string
+	for i, rule := range rules {
+		if rule.Category == "" {
+			errs = append(errs, fmt.Sprintf("第 %d 条规则缺少 category", i+1))
+			continue
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("分类 %s 的正则无效: %v", rule.Category, err))
+			continue
+		}
+		match := rule.Match
+		if len(match) == 0 {
+			match = []string{CategoryMatchName, CategoryMatchDescription}
+		}
+		for _, field := range match {
+			if field != CategoryMatchName && field != CategoryMatchDescription && field != CategoryMatchItems {
+				errs = append(errs, fmt.Sprintf("分类 %s 的匹配范围无效: %s", rule.Category, field))
+			}
+		}
+		compiled = append(compiled, categoryRule{category: rule.Category, pattern: re, match: match})
+	}
+
+	if len(errs) > 0 {
+		return compiled, fmt.Errorf("分类规则配置错误: %s", strings.Join(errs, "；"))
+	}
+	return compiled, nil
+}
+
+func (r categoryRule) matches(group *EnumGroup) bool {
+	for _, field := range r.match {
+		switch field {
+		case CategoryMatchName:
+			if r.pattern.MatchString(group.Name) {
+				return true
+			}
+		case CategoryMatchDescription:
+			if r.pattern.MatchString(group.Description) {
+				return true
+			}
+		case CategoryMatchItems:
+			for _, item := range group.Items {
+				if r.pattern.MatchString(item.Name) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// categoryCount 某个分类下的枚举数量
+type categoryCount struct {
+	Category string
+	Count    int
+}
+
+// 按数量从多到少统计枚举分类，数量相同按分类名排序
+func (p *Parser) categoryCounts() []categoryCount {
+	counts := make(map[string]int)
+	for _, group := range p.enums {
+		counts[group.Category]++
+	}
+
+	result := make([]categoryCount, 0, len(counts))
+	for category, count := range counts {
+		result = append(result, categoryCount{Category: orDash(category), Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Category < result[j].Category
+	})
+	return result
+}
@@ -0,0 +1,129 @@
+package docgen
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config docgen 配置
+type Config struct {
+	RepoRoot   string          `yaml:"repo_root"` // 仓库根目录，为空时以解析目录为根
+	Include    []string        `yaml:"include"`   // 只解析匹配的文件，相对解析目录的 glob，支持 **
+	Exclude    []string        `yaml:"exclude"`   // 跳过匹配的文件
+	Permalink  PermalinkConfig `yaml:"permalink"`
+	ErrorCodes ErrorCodeConfig `yaml:"error_codes"`
+	ConfigDoc  ConfigDocConfig `yaml:"config_doc"`
+	Gen        GenConfig       `yaml:"gen"`
+	Segment    SegmentConfig   `yaml:"segment"`
+	Category   CategoryConfig  `yaml:"category"`
+}
+
+// CategoryConfig 枚举分类规则，按顺序匹配，第一个命中的规则决定分类
+type CategoryConfig struct {
+	Rules   []CategoryRule `yaml:"rules"`   // 自定义规则，先于内置规则匹配
+	Builtin *bool          `yaml:"builtin"` // 是否启用内置规则，默认启用
+	Default string         `yaml:"default"` // 没有规则命中时的分类
+}
+
+// CategoryRule 一条分类规则
+type CategoryRule struct {
+	Category string   `yaml:"category"` // 命中后的分类
+	Pattern  string   `yaml:"pattern"`  // 正则表达式，如 (?i)pay|支付
+	Match    []string `yaml:"match"`    // 匹配范围：name、description、items，默认为 name 和 description
+}
+
+// SegmentConfig 分词配置，用于从枚举名称和注释中提取搜索标签
+type SegmentConfig struct {
+	DictFiles     []string `yaml:"dict_files"`     // 自定义词典，每行“词 [词频]”
+	StopwordFiles []string `yaml:"stopword_files"` // 停用词表，每行一个词
+	Words         []string `yaml:"words"`          // 补充的领域词
+	Stopwords     []string `yaml:"stopwords"`      // 补充的停用词
+}
+
+// PermalinkConfig 源码链接配置
+type PermalinkConfig struct {
+	// URLPattern 链接模板，支持 {commit}、{path}、{start}、{end} 占位符，
+	// 如 https://github.com/org/repo/blob/{commit}/{path}#L{start}-L{end}
+	URLPattern string `yaml:"url_pattern"`
+	Commit     string `yaml:"commit"` // 提交哈希或分支名
+}
+
+// ErrorCodeConfig 错误码提取配置
+type ErrorCodeConfig struct {
+	RegisterFuncs []string `yaml:"register_funcs"` // 注册错误码的函数，如 errcode.New(40013, "...")
+	ErrorFuncs    []string `yaml:"error_funcs"`    // 创建错误变量的函数，如 errors.New("...")
+	ConstPrefixes []string `yaml:"const_prefixes"` // 错误码常量名前缀，如 CodeXxx = 40013
+}
+
+// ConfigDocConfig 配置结构体提取配置
+type ConfigDocConfig struct {
+	Types []string `yaml:"types"` // 需要提取的配置结构体，Type 或 pkg.Type，标记 @ai:config 的结构体总会提取
+}
+
+// GenConfig 代码生成配置
+type GenConfig struct {
+	TS  TSGenConfig  `yaml:"ts"`
+	SQL SQLGenConfig `yaml:"sql"`
+}
+
+// TSGenConfig TypeScript 枚举生成配置
+type TSGenConfig struct {
+	Layout   string `yaml:"layout"`    // package：每个包一个文件；single：全部写入一个文件
+	Style    string `yaml:"style"`     // enum：export enum；const：as const 对象
+	FileName string `yaml:"file_name"` // single 布局下的文件名
+}
+
+// SQLGenConfig 数据库约束生成配置
+type SQLGenConfig struct {
+	Dialect  string `yaml:"dialect"`   // 数据库方言，目前只支持 postgres
+	Mode     string `yaml:"mode"`      // check：CHECK 约束；enum：CREATE TYPE ... AS ENUM
+	Schema   string `yaml:"schema"`    // 建表语句未指定 schema 时使用的 schema
+	FileName string `yaml:"file_name"` // 输出文件名
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		ErrorCodes: ErrorCodeConfig{
+			RegisterFuncs: []string{"errcode.New", "errcode.NewError", "ecode.New", "errors.NewCode"},
+			ErrorFuncs:    []string{"errors.New", "fmt.Errorf", "errors.Errorf"},
+			ConstPrefixes: []string{"Code", "ErrCode", "ErrorCode"},
+		},
+		Category: CategoryConfig{
+			Default: "其他",
+		},
+		Gen: GenConfig{
+			TS: TSGenConfig{
+				Layout:   TSLayoutPackage,
+				Style:    TSStyleEnum,
+				FileName: "enums.ts",
+			},
+			SQL: SQLGenConfig{
+				Dialect:  "postgres",
+				Mode:     SQLModeCheck,
+				Schema:   "public",
+				FileName: "enum_constraints.sql",
+			},
+		},
+	}
+}
+
+// LoadConfig 从文件加载配置，未设置的项使用默认值
+func LoadConfig(filePath string) (*Config, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	config := DefaultConfig()
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if _, err := compileCategoryRules(config.Category); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,416 @@
+package docgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 配置结构体的标记
+const configAnnotation = "@ai:config"
+
+// 配置结构体展开的最大嵌套深度
+const maxConfigDepth = 10
+
+// ConfigStruct 表示一个配置结构体及其展开后的配置项
+type ConfigStruct struct {
+	Name    string      `json:"name"`    // 结构体名称
+	Package string      `json:"package"` // 包名
+	Doc     string      `json:"doc"`     // 结构体注释
+	Keys    []ConfigKey `json:"keys"`    // 配置项
+	SourceLocation
+}
+
+// ConfigKey 表示一个配置项
+type ConfigKey struct {
+	Key     string `json:"key"`     // 点分隔的配置键，如 vector_store.url
+	Type    string `json:"type"`    // Go 类型
+	Doc     string `json:"doc"`     // 字段注释
+	Default string `json:"default"` // 默认值
+	SourceLocation
+}
+
+type configType struct {
+	spec     *ast.TypeSpec
+	doc      string
+	pkg      string
+	rootPath string
+	filename string
+}
+
+type configScan struct {
+	types map[string]*configType // pkg.Type -> 类型声明
+	funcs []*configFunc
+}
+
+type configFunc struct {
+	decl *ast.FuncDecl
+	pkg  string
+}
+
+// 字段名到配置键的映射，用于把默认值构造函数中的字面量对应到配置键
+type configField struct {
+	key      string
+	typeName string // 嵌套结构体的 pkg.Type，非结构体为空
+}
+
+// ParseConfigStructs 提取标记了 @ai:config 或在配置中指定类型名的配置结构体，
+// 按 yaml 标签展开为点分隔的配置键，并从 GetDefaultConfig 之类的构造函数中读取默认值
+func (p *Parser) ParseConfigStructs(rootPath string) ([]ConfigStruct, error) {
+	scan := &configScan{types: make(map[string]*configType)}
+
+	err := p.walkFiles(rootPath, ".go", func(filename string) error {
+		node, err := p.parseGoFile(filename)
+		if err != nil {
+			return err
+		}
+		pkgName := node.Name.Name
+		for _, decl := range node.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range d.Specs {
+					tspec := spec.(*ast.TypeSpec)
+					doc := tspec.Doc
+					if doc == nil && len(d.Specs) == 1 {
+						doc = d.Doc
+					}
+					scan.types[pkgName+"."+tspec.Name.Name] = &configType{
+						spec:     tspec,
+						doc:      strings.TrimPrefix(commentText(doc), tspec.Name.Name+" "),
+						pkg:      pkgName,
+						rootPath: rootPath,
+						filename: filename,
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Body != nil && d.Recv == nil && strings.Contains(d.Name.Name, "Default") {
+					scan.funcs = append(scan.funcs, &configFunc{decl: d, pkg: pkgName})
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return p.configStructs, err
+	}
+
+	var names []string
+	for name, t := range scan.types {
+		if _, ok := t.spec.Type.(*ast.StructType); !ok {
+			continue
+		}
+		if strings.Contains(t.doc, configAnnotation) ||
+			containsString(p.config.ConfigDoc.Types, name) ||
+			containsString(p.config.ConfigDoc.Types, t.spec.Name.Name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	p.configStructs = nil
+	for _, name := range names {
+		t := scan.types[name]
+		line := p.fset.Position(t.spec.Pos()).Line
+		cs := ConfigStruct{
+			Name:           t.spec.Name.Name,
+			Package:        t.pkg,
+			Doc:            strings.TrimSpace(strings.ReplaceAll(t.doc, configAnnotation, "")),
+			SourceLocation: p.location(t.rootPath, t.filename, line, p.fset.Position(t.spec.End()).Line),
+		}
+
+		fields := make(map[string]map[string]configField)
+		p.flattenConfig(scan, t, t.spec.Type.(*ast.StructType), "", 0, &cs.Keys, fields)
+
+		defaults := make(map[string]string)
+		for _, fn := range scan.funcs {
+			if lit := defaultConfigLiteral(fn, name); lit != nil {
+				p.collectDefaults(lit, name, fields, defaults)
+			}
+		}
+		for i := range cs.Keys {
+			if v, ok := defaults[cs.Keys[i].Key]; ok {
+				cs.Keys[i].Default = v
+			}
+		}
+
+		p.configStructs = append(p.configStructs, cs)
+	}
+
+	return p.configStructs, nil
+}
+
+// 递归展开结构体字段
+func (p *Parser) flattenConfig(scan *configScan, owner *configType, st *ast.StructType, prefix string, depth int,
+	keys *[]ConfigKey, fields map[string]map[string]configField) {
+	if depth > maxConfigDepth {
+		return
+	}
+	ownerKey := owner.pkg + "." + owner.spec.Name.Name
+	if fields[ownerKey] == nil {
+		fields[ownerKey] = make(map[string]configField)
+	}
+
+	for _, field := range st.Fields.List {
+		name, inline, skip := yamlFieldName(field)
+		if skip {
+			continue
+		}
+
+		key := joinConfigKey(prefix, name)
+		if inline {
+			key = prefix
+		}
+
+		// 解析字段类型，区分嵌套结构体、结构体切片和映射
+		typeExpr := field.Type
+		suffix := ""
+		for {
+			switch t := typeExpr.(type) {
+			case *ast.StarExpr:
+				typeExpr = t.X
+				continue
+			case *ast.ArrayType:
+				// []Item 与 []*Item
+				elt := stripStar(t.Elt)
+				if _, ok := structTypeOf(scan, owner.pkg, elt); ok {
+					typeExpr, suffix = elt, "[]"
+				}
+			case *ast.MapType:
+				value := stripStar(t.Value)
+				if _, ok := structTypeOf(scan, owner.pkg, value); ok {
+					typeExpr, suffix = value, ".*"
+				}
+			}
+			break
+		}
+		nested, isStruct := structTypeOf(scan, owner.pkg, typeExpr)
+
+		doc := commentText(field.Doc)
+		if doc == "" {
+			doc = commentText(field.Comment)
+		}
+		if doc == "" && isStruct && nested.doc != "" {
+			doc = strings.TrimSpace(strings.ReplaceAll(nested.doc, configAnnotation, ""))
+		}
+
+		if !inline {
+			line := p.fset.Position(field.Pos()).Line
+			*keys = append(*keys, ConfigKey{
+				Key:            key,
+				Type:           p.nodeString(field.Type),
+				Doc:            doc,
+				SourceLocation: p.location(owner.rootPath, owner.filename, line, line),
+			})
+		}
+
+		// 只有直接嵌套的具名结构体才能从默认值字面量继续展开
+		nestedKey := ""
+		if isStruct {
+			if anon, ok := typeExpr.(*ast.StructType); ok {
+				// 匿名结构体沿用外层类型定位源码
+				p.flattenConfig(scan, owner, anon, key+suffix, depth+1, keys, fields)
+			} else {
+				p.flattenConfig(scan, nested, nested.spec.Type.(*ast.StructType), key+suffix, depth+1, keys, fields)
+				if suffix == "" {
+					nestedKey = nested.pkg + "." + nested.spec.Name.Name
+				}
+			}
+		}
+		for _, fieldName := range field.Names {
+			fields[ownerKey][fieldName.Name] = configField{key: key, typeName: nestedKey}
+		}
+	}
+}
+
+// 去掉指针类型的 *
+func stripStar(expr ast.Expr) ast.Expr {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		return star.X
+	}
+	return expr
+}
+
+// 查找类型表达式对应的结构体声明
+func structTypeOf(scan *configScan, pkg string, expr ast.Expr) (*configType, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		ct, ok := scan.types[pkg+"."+t.Name]
+		if !ok {
+			return nil, false
+		}
+		_, isStruct := ct.spec.Type.(*ast.StructType)
+		return ct, isStruct
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			return structTypeOf(scan, x.Name, t.Sel)
+		}
+	case *ast.StructType:
+		return &configType{spec: &ast.TypeSpec{Name: ast.NewIdent(""), Type: t}, pkg: pkg}, true
+	}
+	return nil, false
+}
+
+// 在默认值构造函数中查找类型为 typeName 的复合字面量
+func defaultConfigLiteral(fn *configFunc, typeName string) *ast.CompositeLit {
+	if fn.decl.Type.Results == nil || len(fn.decl.Type.Results.List) == 0 {
+		return nil
+	}
+	result := fn.decl.Type.Results.List[0].Type
+	if star, ok := result.(*ast.StarExpr); ok {
+		result = star.X
+	}
+	if !typeMatches(result, fn.pkg, typeName) {
+		return nil
+	}
+
+	var found *ast.CompositeLit
+	ast.Inspect(fn.decl.Body, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		if lit, ok := n.(*ast.CompositeLit); ok && lit.Type != nil && typeMatches(lit.Type, fn.pkg, typeName) {
+			found = lit
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func typeMatches(expr ast.Expr, pkg, typeName string) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return pkg+"."+t.Name == typeName
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			return x.Name+"."+t.Sel.Name == typeName
+		}
+	}
+	return false
+}
+
+// 把复合字面量中的字段值对应到配置键
+func (p *Parser) collectDefaults(lit *ast.CompositeLit, typeName string, fields map[string]map[string]configField, defaults map[string]string) {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		ident, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		field, ok := fields[typeName][ident.Name]
+		if !ok {
+			continue
+		}
+
+		value := kv.Value
+		if unary, ok := value.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			value = unary.X
+		}
+		if nested, ok := value.(*ast.CompositeLit); ok && field.typeName != "" {
+			p.collectDefaults(nested, field.typeName, fields, defaults)
+			continue
+		}
+		defaults[field.key] = p.nodeString(kv.Value)
+	}
+}
+
+// 解析字段的 yaml 名称，未设置标签时与 yaml.v3 一致使用小写字段名
+func yamlFieldName(field *ast.Field) (name string, inline, skip bool) {
+	var tag string
+	if field.Tag != nil {
+		if raw, err := strconv.Unquote(field.Tag.Value); err == nil {
+			tag = reflect.StructTag(raw).Get("yaml")
+		}
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+	if parts[0] == "-" {
+		return "", false, true
+	}
+
+	if len(field.Names) == 0 {
+		// 嵌入字段没有标签名时按 inline 处理
+		return "", parts[0] == "" || inline, false
+	}
+	if !field.Names[0].IsExported() {
+		return "", false, true
+	}
+	if parts[0] != "" {
+		return parts[0], inline, false
+	}
+	return strings.ToLower(field.Names[0].Name), inline, false
+}
+
+func joinConfigKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(group.Text()), " ")
+}
+
+// 使用 go/printer 输出节点的源码形式
+func (p *Parser) nodeString(node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, p.fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// 生成配置项文档
+func (p *Parser) writeConfigStructs(md *strings.Builder) {
+	if len(p.configStructs) == 0 {
+		return
+	}
+
+	writeRAGMeta(md, map[string]string{"doc_type": "config"})
+	md.WriteString("# 配置项\n\n")
+	for _, cs := range p.configStructs {
+		writeRAGMeta(md, map[string]string{
+			"doc_type": "config",
+			"package":  cs.Package,
+			"name":     cs.Name,
+		})
+		if cs.Doc != "" {
+			md.WriteString(fmt.Sprintf("## %s.%s（%s）\n\n", cs.Package, cs.Name, cs.Doc))
+		} else {
+			md.WriteString(fmt.Sprintf("## %s.%s\n\n", cs.Package, cs.Name))
+		}
+		if cs.File != "" {
+			md.WriteString(fmt.Sprintf("**来源：** %s\n\n", cs.SourceLocation.Markdown()))
+		}
+		md.WriteString("| 配置键 | 类型 | 默认值 | 说明 |\n|---|---|---|---|\n")
+		for _, key := range cs.Keys {
+			defaultValue := "-"
+			if key.Default != "" {
+				defaultValue = "`" + tableCell(key.Default) + "`"
+			}
+			md.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				tableCell(key.Key), "`"+tableCell(key.Type)+"`", defaultValue, orDash(tableCell(key.Doc))))
+		}
+		md.WriteString("\n")
+	}
+}
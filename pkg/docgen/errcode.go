@@ -0,0 +1,516 @@
+package docgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrorCode 表示一条错误码定义
+type ErrorCode struct {
+	Code       string `json:"code"`        // 错误码，哨兵错误为空
+	Name       string `json:"name"`        // 错误变量或常量名
+	Const      string `json:"const"`       // 错误码对应的常量名
+	Message    string `json:"message"`     // 错误信息
+	HTTPStatus int    `json:"http_status"` // 对应的 HTTP 状态码
+	Package    string `json:"package"`     // 包名
+	SourceLocation
+}
+
+// 提取过程中的中间状态
+type errorCodeScan struct {
+	entries  []*ErrorCode
+	codeRefs map[*ErrorCode]string          // 错误码引用的常量，形如 pkg.Name
+	consts   map[string]string              // pkg.Name -> 整数常量值
+	messages map[string]string              // pkg.Name 或 pkg.#code -> 错误信息
+	handled  map[*ast.CallExpr]bool         // 已处理的注册调用
+	byConst  map[string][]*ErrorCode        // 常量定义产生的错误码
+	names    map[string]map[string]struct{} // 常量名 -> 所在包，用于跨包解析
+}
+
+// ParseErrorCodes 提取错误码目录，识别错误码常量、错误码注册调用和 errors.New 形式的哨兵错误
+func (p *Parser) ParseErrorCodes(rootPath string) ([]ErrorCode, error) {
+	scan := &errorCodeScan{
+		codeRefs: make(map[*ErrorCode]string),
+		consts:   make(map[string]string),
+		messages: make(map[string]string),
+		handled:  make(map[*ast.CallExpr]bool),
+		byConst:  make(map[string][]*ErrorCode),
+		names:    make(map[string]map[string]struct{}),
+	}
+
+	err := p.walkFiles(rootPath, ".go", func(path string) error {
+		node, err := p.parseGoFile(path)
+		if err != nil {
+			return err
+		}
+		p.scanErrorCodes(scan, node, rootPath, path)
+		return nil
+	})
+	if err != nil {
+		return p.errorCodes, err
+	}
+
+	p.errorCodes = scan.finish()
+	return p.errorCodes, nil
+}
+
+func (p *Parser) scanErrorCodes(scan *errorCodeScan, node *ast.File, rootPath, filename string) {
+	pkgName := node.Name.Name
+	cfg := p.config.ErrorCodes
+
+	for _, decl := range node.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vspec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for i, name := range vspec.Names {
+				if i >= len(vspec.Values) {
+					continue
+				}
+				loc := p.location(rootPath, filename,
+					p.fset.Position(name.Pos()).Line, p.fset.Position(vspec.End()).Line)
+
+				switch gen.Tok {
+				case token.CONST:
+					value, ok := intLiteral(vspec.Values[i])
+					if !ok {
+						continue
+					}
+					key := pkgName + "." + name.Name
+					scan.consts[key] = value
+					if scan.names[name.Name] == nil {
+						scan.names[name.Name] = make(map[string]struct{})
+					}
+					scan.names[name.Name][pkgName] = struct{}{}
+
+					if hasNamePrefix(name.Name, cfg.ConstPrefixes) {
+						entry := &ErrorCode{
+							Code:           value,
+							Name:           name.Name,
+							Const:          name.Name,
+							Message:        specComment(vspec),
+							Package:        pkgName,
+							SourceLocation: loc,
+						}
+						scan.entries = append(scan.entries, entry)
+						scan.byConst[key] = append(scan.byConst[key], entry)
+					}
+
+				case token.VAR:
+					call, ok := vspec.Values[i].(*ast.CallExpr)
+					if !ok {
+						continue
+					}
+					fn := calleeName(call.Fun)
+					switch {
+					case containsString(cfg.RegisterFuncs, fn):
+						entry := p.registerCallEntry(scan, call, pkgName, loc)
+						entry.Name = name.Name
+						if entry.Message == "" {
+							entry.Message = specComment(vspec)
+						}
+					case containsString(cfg.ErrorFuncs, fn):
+						message, _ := stringArg(call.Args, 0)
+						scan.entries = append(scan.entries, &ErrorCode{
+							Name:           name.Name,
+							Message:        message,
+							Package:        pkgName,
+							SourceLocation: loc,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	// 其余位置的注册调用（如 init 函数中）以及错误信息映射表
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if !scan.handled[n] && containsString(cfg.RegisterFuncs, calleeName(n.Fun)) {
+				line := p.fset.Position(n.Pos()).Line
+				p.registerCallEntry(scan, n, pkgName,
+					p.location(rootPath, filename, line, p.fset.Position(n.End()).Line))
+			}
+		case *ast.CompositeLit:
+			collectMessageMap(scan, n, pkgName)
+		}
+		return true
+	})
+}
+
+// 解析注册调用的参数：第一个整数为错误码，字符串为错误信息，http.StatusXxx 或 100-599 之间的其他整数为 HTTP 状态码
+func (p *Parser) registerCallEntry(scan *errorCodeScan, call *ast.CallExpr, pkgName string, loc SourceLocation) *ErrorCode {
+	scan.handled[call] = true
+	entry := &ErrorCode{
+		Package:        pkgName,
+		SourceLocation: loc,
+	}
+
+	var strs []string
+	var hasCode bool
+	for _, arg := range call.Args {
+		if status, ok := httpStatusArg(arg); ok {
+			entry.HTTPStatus = status
+			continue
+		}
+		if value, ok := intLiteral(arg); ok {
+			if !hasCode {
+				entry.Code, hasCode = value, true
+			} else if status, err := strconv.Atoi(value); err == nil && status >= 100 && status <= 599 {
+				entry.HTTPStatus = status
+			}
+			continue
+		}
+		if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if s, err := strconv.Unquote(lit.Value); err == nil {
+				strs = append(strs, s)
+			}
+			continue
+		}
+		if ref := constRef(arg, pkgName); ref != "" && !hasCode {
+			scan.codeRefs[entry] = ref
+			hasCode = true
+		}
+	}
+
+	switch {
+	case len(strs) >= 2 && !hasCode:
+		entry.Code, entry.Message = strs[0], strs[1]
+	case len(strs) >= 1:
+		entry.Message = strs[0]
+	}
+
+	scan.entries = append(scan.entries, entry)
+	return entry
+}
+
+// 收集 map[int]string{CodeXxx: "..."} 形式的错误信息映射
+func collectMessageMap(scan *errorCodeScan, lit *ast.CompositeLit, pkgName string) {
+	mapType, ok := lit.Type.(*ast.MapType)
+	if !ok {
+		return
+	}
+	if ident, ok := mapType.Value.(*ast.Ident); !ok || ident.Name != "string" {
+		return
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		message, ok := stringArg([]ast.Expr{kv.Value}, 0)
+		if !ok {
+			continue
+		}
+		if value, ok := intLiteral(kv.Key); ok {
+			scan.messages[pkgName+".#"+value] = message
+		} else if ref := constRef(kv.Key, pkgName); ref != "" {
+			scan.messages[ref] = message
+		}
+	}
+}
+
+// 解析常量引用、合并重复定义并排序
+func (s *errorCodeScan) finish() []ErrorCode {
+	absorbed := make(map[*ErrorCode]bool)
+	for _, entry := range s.entries {
+		ref, ok := s.codeRefs[entry]
+		if !ok {
+			continue
+		}
+		ref = s.resolveRef(ref)
+		value, ok := s.consts[ref]
+		if !ok {
+			continue
+		}
+		entry.Code = value
+		entry.Const = ref[strings.LastIndex(ref, ".")+1:]
+
+		// 注册调用引用的常量不再单独列出
+		for _, constEntry := range s.byConst[ref] {
+			absorbed[constEntry] = true
+			if entry.Message == "" {
+				entry.Message = constEntry.Message
+			}
+		}
+		if entry.Message == "" {
+			entry.Message = s.messages[ref]
+		}
+	}
+
+	var result []ErrorCode
+	for _, entry := range s.entries {
+		if absorbed[entry] {
+			continue
+		}
+		if _, isRef := s.codeRefs[entry]; !isRef && entry.Const != "" {
+			if message, ok := s.messages[entry.Package+"."+entry.Const]; ok {
+				entry.Message = message
+			}
+		}
+		if entry.Message == "" && entry.Code != "" {
+			entry.Message = s.messages[entry.Package+".#"+entry.Code]
+		}
+		result = append(result, *entry)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if (a.Code == "") != (b.Code == "") {
+			return a.Code != ""
+		}
+		if len(a.Code) != len(b.Code) {
+			return len(a.Code) < len(b.Code)
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Name < b.Name
+	})
+	return result
+}
+
+// 同包找不到时，按常量名在其他包中唯一匹配
+func (s *errorCodeScan) resolveRef(ref string) string {
+	if _, ok := s.consts[ref]; ok {
+		return ref
+	}
+	name := ref[strings.LastIndex(ref, ".")+1:]
+	if pkgs := s.names[name]; len(pkgs) == 1 {
+		for pkg := range pkgs {
+			return pkg + "." + name
+		}
+	}
+	return ref
+}
+
+// 生成错误码文档
+func (p *Parser) writeErrorCodes(md *strings.Builder) {
+	if len(p.errorCodes) == 0 {
+		return
+	}
+
+	writeRAGMeta(md, map[string]string{"doc_type": "error_code"})
+	md.WriteString("# 错误码\n\n")
+	md.WriteString("| 错误码 | 名称 | 信息 | HTTP状态 | 包 | 来源 |\n|---|---|---|---|---|---|\n")
+	for _, code := range p.errorCodes {
+		name := code.Name
+		if code.Const != "" && code.Const != code.Name {
+			if name == "" {
+				name = code.Const
+			} else {
+				name = fmt.Sprintf("%s（%s）", name, code.Const)
+			}
+		}
+		status := "-"
+		if code.HTTPStatus != 0 {
+			status = strconv.Itoa(code.HTTPStatus)
+		}
+		md.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+			orDash(tableCell(code.Code)), orDash(tableCell(name)), orDash(tableCell(code.Message)), status,
+			code.Package, code.SourceLocation.Markdown()))
+	}
+	md.WriteString("\n")
+}
+
+// 返回调用的函数名，形如 pkg.Func 或 Func
+func calleeName(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		if x, ok := f.X.(*ast.Ident); ok {
+			return x.Name + "." + f.Sel.Name
+		}
+		return f.Sel.Name
+	}
+	return ""
+}
+
+// 解析整数字面量，返回十进制字符串
+func intLiteral(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.INT {
+			return "", false
+		}
+		v, err := strconv.ParseInt(strings.ReplaceAll(e.Value, "_", ""), 0, 64)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatInt(v, 10), true
+	case *ast.UnaryExpr:
+		if e.Op == token.SUB {
+			if v, ok := intLiteral(e.X); ok {
+				return "-" + v, true
+			}
+		}
+	case *ast.ParenExpr:
+		return intLiteral(e.X)
+	}
+	return "", false
+}
+
+// 返回常量引用的 pkg.Name 形式
+func constRef(expr ast.Expr, pkgName string) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return pkgName + "." + e.Name
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			return x.Name + "." + e.Sel.Name
+		}
+	}
+	return ""
+}
+
+func stringArg(args []ast.Expr, i int) (string, bool) {
+	if i >= len(args) {
+		return "", false
+	}
+	lit, ok := args[i].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// net/http 中的状态码常量，只读，可以在并发解析时共享
+var httpStatusNames = map[string]int{
+	"StatusContinue":                      http.StatusContinue,
+	"StatusSwitchingProtocols":            http.StatusSwitchingProtocols,
+	"StatusProcessing":                    http.StatusProcessing,
+	"StatusEarlyHints":                    http.StatusEarlyHints,
+	"StatusOK":                            http.StatusOK,
+	"StatusCreated":                       http.StatusCreated,
+	"StatusAccepted":                      http.StatusAccepted,
+	"StatusNonAuthoritativeInfo":          http.StatusNonAuthoritativeInfo,
+	"StatusNoContent":                     http.StatusNoContent,
+	"StatusResetContent":                  http.StatusResetContent,
+	"StatusPartialContent":                http.StatusPartialContent,
+	"StatusMultiStatus":                   http.StatusMultiStatus,
+	"StatusAlreadyReported":               http.StatusAlreadyReported,
+	"StatusIMUsed":                        http.StatusIMUsed,
+	"StatusMultipleChoices":               http.StatusMultipleChoices,
+	"StatusMovedPermanently":              http.StatusMovedPermanently,
+	"StatusFound":                         http.StatusFound,
+	"StatusSeeOther":                      http.StatusSeeOther,
+	"StatusNotModified":                   http.StatusNotModified,
+	"StatusUseProxy":                      http.StatusUseProxy,
+	"StatusTemporaryRedirect":             http.StatusTemporaryRedirect,
+	"StatusPermanentRedirect":             http.StatusPermanentRedirect,
+	"StatusBadRequest":                    http.StatusBadRequest,
+	"StatusUnauthorized":                  http.StatusUnauthorized,
+	"StatusPaymentRequired":               http.StatusPaymentRequired,
+	"StatusForbidden":                     http.StatusForbidden,
+	"StatusNotFound":                      http.StatusNotFound,
+	"StatusMethodNotAllowed":              http.StatusMethodNotAllowed,
+	"StatusNotAcceptable":                 http.StatusNotAcceptable,
+	"StatusProxyAuthRequired":             http.StatusProxyAuthRequired,
+	"StatusRequestTimeout":                http.StatusRequestTimeout,
+	"StatusConflict":                      http.StatusConflict,
+	"StatusGone":                          http.StatusGone,
+	"StatusLengthRequired":                http.StatusLengthRequired,
+	"StatusPreconditionFailed":            http.StatusPreconditionFailed,
+	"StatusRequestEntityTooLarge":         http.StatusRequestEntityTooLarge,
+	"StatusRequestURITooLong":             http.StatusRequestURITooLong,
+	"StatusUnsupportedMediaType":          http.StatusUnsupportedMediaType,
+	"StatusRequestedRangeNotSatisfiable":  http.StatusRequestedRangeNotSatisfiable,
+	"StatusExpectationFailed":             http.StatusExpectationFailed,
+	"StatusTeapot":                        http.StatusTeapot,
+	"StatusMisdirectedRequest":            http.StatusMisdirectedRequest,
+	"StatusUnprocessableEntity":           http.StatusUnprocessableEntity,
+	"StatusLocked":                        http.StatusLocked,
+	"StatusFailedDependency":              http.StatusFailedDependency,
+	"StatusTooEarly":                      http.StatusTooEarly,
+	"StatusUpgradeRequired":               http.StatusUpgradeRequired,
+	"StatusPreconditionRequired":          http.StatusPreconditionRequired,
+	"StatusTooManyRequests":               http.StatusTooManyRequests,
+	"StatusRequestHeaderFieldsTooLarge":   http.StatusRequestHeaderFieldsTooLarge,
+	"StatusUnavailableForLegalReasons":    http.StatusUnavailableForLegalReasons,
+	"StatusInternalServerError":           http.StatusInternalServerError,
+	"StatusNotImplemented":                http.StatusNotImplemented,
+	"StatusBadGateway":                    http.StatusBadGateway,
+	"StatusServiceUnavailable":            http.StatusServiceUnavailable,
+	"StatusGatewayTimeout":                http.StatusGatewayTimeout,
+	"StatusHTTPVersionNotSupported":       http.StatusHTTPVersionNotSupported,
+	"StatusVariantAlsoNegotiates":         http.StatusVariantAlsoNegotiates,
+	"StatusInsufficientStorage":           http.StatusInsufficientStorage,
+	"StatusLoopDetected":                  http.StatusLoopDetected,
+	"StatusNotExtended":                   http.StatusNotExtended,
+	"StatusNetworkAuthenticationRequired": http.StatusNetworkAuthenticationRequired,
+}
+
+// 识别 http.StatusXxx 形式的参数
+func httpStatusArg(expr ast.Expr) (int, bool) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return 0, false
+	}
+	if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "http" {
+		return 0, false
+	}
+	code, ok := httpStatusNames[sel.Sel.Name]
+	return code, ok
+}
+
+// 获取常量声明的行尾注释或文档注释
+func specComment(vspec *ast.ValueSpec) string {
+	if vspec.Comment != nil {
+		return strings.TrimSpace(vspec.Comment.Text())
+	}
+	if vspec.Doc != nil {
+		return strings.TrimSpace(vspec.Doc.Text())
+	}
+	return ""
+}
+
+// 名称以指定前缀开头，且前缀后紧跟大写字母或数字
+func hasNamePrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if len(name) > len(prefix) && strings.HasPrefix(name, prefix) {
+			next := rune(name[len(prefix)])
+			if unicode.IsUpper(next) || unicode.IsDigit(next) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// 转换为 Markdown 表格单元格：换行合并为空格，转义竖线，避免破坏表格和按表格的分块
+func tableCell(s string) string {
+	return strings.ReplaceAll(oneLine(s), "|", `\|`)
+}
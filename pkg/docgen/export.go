@@ -0,0 +1,278 @@
+package docgen
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 列宽范围，单位为字符
+const (
+	minColumnWidth = 8
+	maxColumnWidth = 60
+)
+
+// sheet 是数据字典中的一张表格
+type sheet struct {
+	Name   string
+	File   string // 导出 CSV 时的文件名
+	Header []string
+	Rows   [][]string
+}
+
+// 数据字典：数据表字段和枚举两张表格
+func (p *Parser) dictionarySheets() []sheet {
+	// 字段关联的枚举
+	linked := make(map[string]*EnumGroup)
+	for _, ec := range p.enumColumns() {
+		linked[ec.Key+"."+ec.Column] = ec.Group
+	}
+
+	tables := sheet{
+		Name:   "数据表",
+		File:   "tables.csv",
+		Header: []string{"表名", "表注释", "字段", "类型", "描述", "关联枚举", "枚举取值", "来源"},
+	}
+	var tableNames []string
+	for name := range p.dbComments {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+	for _, name := range tableNames {
+		table := p.dbComments[name]
+		for _, field := range table.Fields {
+			enumName, values := "", ""
+			if group, ok := linked[name+"."+field.FieldName]; ok {
+				enumName = enumTypeName(group)
+				values = enumValueList(group)
+			}
+			tables.Rows = append(tables.Rows, []string{
+				name, table.Comment, field.FieldName, field.FieldType, field.Comment,
+				enumName, values, table.SourceLocation.String(),
+			})
+		}
+	}
+
+	enums := sheet{
+		Name:   "枚举",
+		File:   "enums.csv",
+		Header: []string{"枚举", "描述", "包", "分类", "变量", "原值", "说明", "数据库列", "来源"},
+	}
+	for _, group := range p.sortedEnums() {
+		for _, item := range group.Items {
+			enums.Rows = append(enums.Rows, []string{
+				enumTypeName(group), oneLine(group.Description), group.Package, group.Category,
+				item.Name, valueText(item.Value), item.Comment,
+				strings.Join(group.Columns, ", "), item.SourceLocation.String(),
+			})
+		}
+	}
+
+	return []sheet{tables, enums}
+}
+
+// 枚举取值列表，格式与数据库列注释一致：init-初始化，pending-待处理
+func enumValueList(group *EnumGroup) string {
+	var parts []string
+	for _, item := range group.Items {
+		label := oneLine(item.Comment)
+		if label == "" {
+			label = item.Name
+		}
+		parts = append(parts, valueText(item.Value)+"-"+label)
+	}
+	return strings.Join(parts, "，")
+}
+
+// 枚举值的展示文本，字符串去掉引号
+func valueText(value interface{}) string {
+	if text, _, ok := literalValue(value); ok {
+		return text
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// ExportCSV 导出数据字典为 CSV 文件，返回文件名到内容的映射。
+// 文件带 UTF-8 BOM，便于 Excel 直接打开中文内容
+func (p *Parser) ExportCSV() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for _, s := range p.dictionarySheets() {
+		var buf bytes.Buffer
+		buf.WriteString("\xef\xbb\xbf")
+		w := csv.NewWriter(&buf)
+		if err := w.Write(s.Header); err != nil {
+			return nil, err
+		}
+		if err := w.WriteAll(s.Rows); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", s.File, err)
+		}
+		files[s.File] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// ExportXLSX 导出数据字典为多工作表的 XLSX 文件，首行冻结并按内容设置列宽
+func (p *Parser) ExportXLSX() ([]byte, error) {
+	sheets := p.dictionarySheets()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name, content string) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(xml.Header + content))
+		return err
+	}
+
+	var overrides, workbookSheets, workbookRels strings.Builder
+	for i, s := range sheets {
+		id := i + 1
+		overrides.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, id))
+		workbookSheets.WriteString(fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(s.Name), id, id))
+		workbookRels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, id, id))
+	}
+	stylesID := len(sheets) + 1
+	workbookRels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, stylesID))
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + workbookSheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			workbookRels.String() + `</Relationships>`},
+		// 样式 0 为默认，样式 1 为加粗的表头
+		{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+			`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for i, s := range sheets {
+		parts = append(parts, struct{ name, content string }{
+			fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheetXML(s),
+		})
+	}
+
+	for _, part := range parts {
+		if err := write(part.name, part.content); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", part.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 生成工作表 XML：冻结首行、设置列宽和自动筛选，单元格使用内联字符串
+func worksheetXML(s sheet) string {
+	var ws strings.Builder
+	ws.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	ws.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+	ws.WriteString(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	ws.WriteString(`</sheetView></sheetViews>`)
+
+	ws.WriteString("<cols>")
+	for i, width := range columnWidths(s) {
+		ws.WriteString(fmt.Sprintf(`<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width))
+	}
+	ws.WriteString("</cols>")
+
+	ws.WriteString("<sheetData>")
+	writeRow := func(r int, cells []string, style int) {
+		ws.WriteString(fmt.Sprintf(`<row r="%d">`, r))
+		for c, value := range cells {
+			ws.WriteString(fmt.Sprintf(`<c r="%s%d" t="inlineStr"`, columnName(c), r))
+			if style > 0 {
+				ws.WriteString(fmt.Sprintf(` s="%d"`, style))
+			}
+			ws.WriteString(fmt.Sprintf(`><is><t xml:space="preserve">%s</t></is></c>`, xmlEscape(value)))
+		}
+		ws.WriteString("</row>")
+	}
+	writeRow(1, s.Header, 1)
+	for i, row := range s.Rows {
+		writeRow(i+2, row, 0)
+	}
+	ws.WriteString("</sheetData>")
+
+	ws.WriteString(fmt.Sprintf(`<autoFilter ref="A1:%s%d"/>`, columnName(len(s.Header)-1), len(s.Rows)+1))
+	ws.WriteString("</worksheet>")
+	return ws.String()
+}
+
+// 按内容的显示宽度计算列宽，中文等宽字符按两个字符计算
+func columnWidths(s sheet) []int {
+	widths := make([]int, len(s.Header))
+	measure := func(cells []string) {
+		for i, cell := range cells {
+			if i >= len(widths) {
+				break
+			}
+			w := 0
+			for _, r := range cell {
+				if unicode.Is(unicode.Han, r) || utf8.RuneLen(r) > 2 {
+					w += 2
+				} else {
+					w++
+				}
+			}
+			if w+2 > widths[i] {
+				widths[i] = w + 2
+			}
+		}
+	}
+	measure(s.Header)
+	for _, row := range s.Rows {
+		measure(row)
+	}
+	for i, w := range widths {
+		widths[i] = min(max(w, minColumnWidth), maxColumnWidth)
+	}
+	return widths
+}
+
+// 列序号转换为 A、B、...、AA 形式的列名
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// 转义 XML 文本，并去掉 XML 不允许的控制字符
+func xmlEscape(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
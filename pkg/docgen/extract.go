@@ -0,0 +1,117 @@
+package docgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 提取器名称
+const (
+	ExtractorEnums   = "enums"   // @ai 枚举
+	ExtractorUsages  = "usages"  // 枚举引用，依赖 enums
+	ExtractorSQL     = "sql"     // 建表语句和字段注释
+	ExtractorErrors  = "errors"  // 错误码
+	ExtractorRoutes  = "routes"  // 接口路由
+	ExtractorConfig  = "config"  // 配置结构体
+	ExtractorOpenAPI = "openapi" // OpenAPI / Swagger 文档
+)
+
+var (
+	goExts      = []string{".go"}
+	sqlExts     = []string{".sql"}
+	openAPIExts = []string{".yaml", ".yml", ".json"}
+)
+
+// 已注册的提取器，按依赖顺序执行
+var extractors = []struct {
+	name  string
+	label string
+	exts  []string // 读取的文件后缀，监听模式据此判断需要关注的文件
+	run   func(p *Parser, rootPath string) error
+}{
+	{ExtractorEnums, "解析枚举", goExts, func(p *Parser, rootPath string) error {
+		_, err := p.ParseEnums(rootPath)
+		return err
+	}},
+	{ExtractorUsages, "扫描枚举引用", goExts, func(p *Parser, rootPath string) error {
+		_, err := p.ParseEnumUsages(rootPath)
+		return err
+	}},
+	{ExtractorSQL, "解析数据库注释", sqlExts, func(p *Parser, rootPath string) error {
+		_, err := p.ParseDBComments(rootPath)
+		return err
+	}},
+	{ExtractorErrors, "提取错误码", goExts, func(p *Parser, rootPath string) error {
+		_, err := p.ParseErrorCodes(rootPath)
+		return err
+	}},
+	{ExtractorRoutes, "提取接口路由", goExts, func(p *Parser, rootPath string) error {
+		_, err := p.ParseRoutes(rootPath)
+		return err
+	}},
+	{ExtractorConfig, "提取配置结构体", goExts, func(p *Parser, rootPath string) error {
+		_, err := p.ParseConfigStructs(rootPath)
+		return err
+	}},
+	{ExtractorOpenAPI, "解析 OpenAPI 文档", openAPIExts, func(p *Parser, rootPath string) error {
+		_, err := p.ParseOpenAPI(rootPath)
+		return err
+	}},
+}
+
+// RunExtractors 按注册顺序执行指定的提取器，names 为空时执行全部
+func (p *Parser) RunExtractors(rootPath string, names []string) error {
+	enabled := make(map[string]bool)
+	for _, name := range names {
+		if !isExtractor(name) {
+			return fmt.Errorf("未知的提取器: %s，可选值: %s", name, strings.Join(ExtractorNames(), ", "))
+		}
+		enabled[name] = true
+	}
+
+	for _, e := range extractors {
+		if len(names) > 0 && !enabled[e.name] {
+			continue
+		}
+		if err := e.run(p, rootPath); err != nil {
+			return fmt.Errorf("%s失败: %w", e.label, err)
+		}
+	}
+	return nil
+}
+
+// ExtractorExtensions 返回指定提取器读取的文件后缀，names 为空时返回全部提取器的
+func ExtractorExtensions(names []string) []string {
+	var exts []string
+	seen := make(map[string]bool)
+	for _, e := range extractors {
+		if len(names) > 0 && !containsString(names, e.name) {
+			continue
+		}
+		for _, ext := range e.exts {
+			if !seen[ext] {
+				seen[ext] = true
+				exts = append(exts, ext)
+			}
+		}
+	}
+	return exts
+}
+
+// ExtractorNames 返回全部提取器名称
+func ExtractorNames() []string {
+	names := make([]string, 0, len(extractors))
+	for _, e := range extractors {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+func isExtractor(name string) bool {
+	for _, e := range extractors {
+		if e.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package docgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 生成文件的头部标记，编辑器和 lint 工具据此识别生成代码
+const generatedHeader = "Code generated by docgen. DO NOT EDIT."
+
+// WriteGenerated 把生成结果写入目录，内容未变化的文件不会重写，返回实际写入的文件
+func WriteGenerated(dir string, files map[string][]byte) ([]string, error) {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var written []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, files[name]) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return written, fmt.Errorf("创建输出目录失败: %w", err)
+		}
+		if err := WriteFileAtomic(path, files[name], 0644); err != nil {
+			return written, fmt.Errorf("写入生成文件失败: %w", err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// WriteFileAtomic 先写入同目录下的临时文件再重命名，读取方不会看到写了一半的文件
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// 按名称排序的枚举组
+func (p *Parser) sortedEnums() []*EnumGroup {
+	var names []string
+	for name := range p.enums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	groups := make([]*EnumGroup, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, p.enums[name])
+	}
+	return groups
+}
+
+// 枚举组的类型名：优先使用常量声明的类型，否则取组名的第一个词
+func enumTypeName(group *EnumGroup) string {
+	if typ := groupGoType(group); typ != "" {
+		return typ
+	}
+	name := group.Name
+	if i := strings.IndexAny(name, " \t"); i >= 0 {
+		name = name[:i]
+	}
+	return exportedName(name)
+}
+
+// 所有枚举项声明为同一类型时返回该类型
+func groupGoType(group *EnumGroup) string {
+	typ := ""
+	for i, item := range group.Items {
+		if i == 0 {
+			typ = item.GoType
+		} else if item.GoType != typ {
+			return ""
+		}
+	}
+	return typ
+}
+
+// 枚举成员名：去掉类型名前缀，去掉后不是合法标识符时保留原名
+func enumMemberName(typeName, itemName string) string {
+	member := strings.TrimPrefix(itemName, typeName)
+	member = strings.TrimLeft(member, "_")
+	if !token.IsIdentifier(member) {
+		member = itemName
+	}
+	if !token.IsIdentifier(member) {
+		member = "_" + exportedName(member)
+	}
+	return member
+}
+
+// 按包分组，包名排序；多仓库合并后包名带上项目前缀
+func groupsByPackage(groups []*EnumGroup) ([]string, map[string][]*EnumGroup) {
+	byPkg := make(map[string][]*EnumGroup)
+	for _, group := range groups {
+		pkg := qualify(group.Project, group.Package)
+		byPkg[pkg] = append(byPkg[pkg], group)
+	}
+	var pkgs []string
+	for pkg := range byPkg {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	return pkgs, byPkg
+}
+
+// 解析枚举项的 Go 字面量，返回去掉引号的值、是否为字符串；只支持字符串和数字
+func literalValue(value interface{}) (string, bool, bool) {
+	s := fmt.Sprintf("%v", value)
+	if s == "" || value == nil {
+		return "", false, false
+	}
+	if strings.HasPrefix(s, "\"") || strings.HasPrefix(s, "`") {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return "", false, false
+		}
+		return unquoted, true, true
+	}
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64); err == nil {
+		return s, false, true
+	}
+	if _, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return s, false, true
+	}
+	return "", false, false
+}
+
+// 驼峰名转换为 snake_case，连续大写视为一个缩写，如 HTTPStatus -> http_status
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,342 @@
+package docgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// 生成文件的后缀，源文件 foo.go 对应 foo_enum_gen.go
+const goGenSuffix = "_enum_gen.go"
+
+// 支持生成方法的底层类型
+var goEnumBaseTypes = map[string]bool{
+	"string": true,
+	"int":    true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+}
+
+// goEnum 是一个待生成方法的具名类型枚举
+type goEnum struct {
+	Type    string
+	Base    string
+	Items   []goEnumItem
+	Skip    map[string]bool // 包中已手写的方法或函数，不再生成
+	Comment string
+}
+
+type goEnumItem struct {
+	Name  string
+	Label string
+}
+
+// GenerateGo 为 @ai 标记的具名类型枚举生成 String、Label、IsValid、ParseXxx、
+// MarshalJSON、UnmarshalJSON 和 XxxValues，结果写在源文件旁的 <file>_enum_gen.go 中。
+// only 不为空时只处理该文件，用于 go:generate。
+// 同时返回过期的生成文件：带 docgen 生成头，但对应源文件已不存在或不再包含可生成的枚举
+func (p *Parser) GenerateGo(rootPath, only string) (map[string][]byte, []string, error) {
+	// 按目录（包）收集源文件和已有的生成文件
+	dirs := make(map[string][]string)
+	genFiles := make(map[string][]string)
+	err := p.walkFiles(rootPath, ".go", func(path string) error {
+		dir := filepath.Dir(path)
+		switch {
+		case strings.HasSuffix(path, goGenSuffix):
+			genFiles[dir] = append(genFiles[dir], path)
+		case !strings.HasSuffix(path, "_test.go"):
+			dirs[dir] = append(dirs[dir], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inScope := func(dir string) bool {
+		return only == "" || filepath.Clean(dir) == filepath.Clean(filepath.Dir(only))
+	}
+	files := make(map[string][]byte)
+	for dir, filenames := range dirs {
+		if !inScope(dir) {
+			continue
+		}
+		if err := p.generateGoPackage(rootPath, filenames, only, files); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var stale []string
+	for dir, paths := range genFiles {
+		if !inScope(dir) {
+			continue
+		}
+		for _, path := range paths {
+			if _, ok := files[path]; ok {
+				continue
+			}
+			source := strings.TrimSuffix(path, goGenSuffix) + ".go"
+			// 只处理单个文件时，其他源文件的生成结果不在本次范围内
+			if _, err := os.Stat(source); err == nil && only != "" && filepath.Base(source) != filepath.Base(only) {
+				continue
+			}
+			if isDocgenGenerated(path) {
+				stale = append(stale, path)
+			}
+		}
+	}
+	sort.Strings(stale)
+	return files, stale, nil
+}
+
+// 判断文件是否带有 docgen 的生成头，手写的同名文件不会被当作过期文件删除
+func isDocgenGenerated(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return bytes.HasPrefix(data, []byte("// "+generatedHeader))
+}
+
+func (p *Parser) generateGoPackage(rootPath string, filenames []string, only string, files map[string][]byte) error {
+	nodes := make(map[string]*ast.File)
+	baseTypes := make(map[string]string)
+	declared := make(map[string]bool)
+	for _, filename := range filenames {
+		node, err := p.parseGoFile(filename)
+		if err != nil {
+			return err
+		}
+		nodes[filename] = node
+		collectGoDecls(node, baseTypes, declared)
+	}
+
+	// 同一类型分布在多个 @ai 块中时只为第一个生成，避免重复声明
+	generated := make(map[string]bool)
+	for _, filename := range filenames {
+		if only != "" && filepath.Base(filename) != filepath.Base(only) {
+			continue
+		}
+		node := nodes[filename]
+
+		var enums []goEnum
+		for _, decl := range node.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			content, ok := aiAnnotation(gen)
+			if !ok {
+				continue
+			}
+			group := p.parseEnumGroup(gen, content, node.Name.Name, rootPath, filename, p.constTypes(node))
+			if group == nil {
+				continue
+			}
+
+			typ := groupGoType(group)
+			base := baseTypes[typ]
+			if typ == "" || !goEnumBaseTypes[base] {
+				fmt.Printf("警告: %s 不是整数或字符串具名类型的枚举，跳过生成\n", group.Name)
+				continue
+			}
+			if generated[typ] {
+				fmt.Printf("警告: %s 已在其他 @ai 块中生成，跳过 %s\n", typ, group.Name)
+				continue
+			}
+			generated[typ] = true
+			enums = append(enums, newGoEnum(group, typ, base, declared))
+		}
+		if len(enums) == 0 {
+			continue
+		}
+
+		src, err := renderGoEnums(node.Name.Name, enums)
+		if err != nil {
+			return fmt.Errorf("生成 %s 失败: %w", filename, err)
+		}
+		files[strings.TrimSuffix(filename, ".go")+goGenSuffix] = src
+	}
+	return nil
+}
+
+// 收集包中具名类型的底层类型，以及已声明的函数和方法（方法记为 Type.Method）
+func collectGoDecls(node *ast.File, baseTypes map[string]string, declared map[string]bool) {
+	for _, decl := range node.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					if ident, ok := ts.Type.(*ast.Ident); ok {
+						baseTypes[ts.Name.Name] = ident.Name
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				declared[d.Name.Name] = true
+			} else {
+				declared[funcDeclName(d)] = true
+			}
+		}
+	}
+}
+
+func newGoEnum(group *EnumGroup, typ, base string, declared map[string]bool) goEnum {
+	enum := goEnum{
+		Type:    typ,
+		Base:    base,
+		Skip:    make(map[string]bool),
+		Comment: group.Description,
+	}
+	for _, name := range []string{"String", "Label", "IsValid", "MarshalJSON", "UnmarshalJSON"} {
+		enum.Skip[name] = declared[typ+"."+name]
+	}
+	enum.Skip["Parse"] = declared["Parse"+typ]
+	enum.Skip["Values"] = declared[typ+"Values"]
+
+	// 同值的常量只保留第一个，避免 map 字面量中出现重复键
+	seen := make(map[string]bool)
+	for _, item := range group.Items {
+		if value := fmt.Sprintf("%v", item.constValue()); item.constValue() != nil {
+			if seen[value] {
+				continue
+			}
+			seen[value] = true
+		}
+		label := oneLine(item.Comment)
+		if label == "" || label == group.Description {
+			label = item.Name
+		}
+		enum.Items = append(enum.Items, goEnumItem{Name: item.Name, Label: label})
+	}
+	return enum
+}
+
+var goEnumTemplate = template.Must(template.New("enum").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`
+{{- range .}}
+{{- $t := .Type}}
+// _{{$t}}Values {{$t}} 的全部取值，按声明顺序
+var _{{$t}}Values = []{{$t}}{
+{{- range .Items}}
+	{{.Name}},
+{{- end}}
+}
+
+// _{{$t}}Names {{$t}} 的常量名
+var _{{$t}}Names = map[{{$t}}]string{
+{{- range .Items}}
+	{{.Name}}: {{quote .Name}},
+{{- end}}
+}
+
+// _{{$t}}Labels {{$t}} 的显示名称
+var _{{$t}}Labels = map[{{$t}}]string{
+{{- range .Items}}
+	{{.Name}}: {{quote .Label}},
+{{- end}}
+}
+{{if not (index .Skip "Values")}}
+// {{$t}}Values 返回{{with .Comment}}{{.}}{{else}} {{$t}} {{end}}的全部取值
+func {{$t}}Values() []{{$t}} {
+	return append([]{{$t}}(nil), _{{$t}}Values...)
+}
+{{end}}
+{{- if not (index .Skip "String")}}
+// String 返回常量名
+func (v {{$t}}) String() string {
+	if name, ok := _{{$t}}Names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("{{$t}}(%v)", {{.Base}}(v))
+}
+{{end}}
+{{- if not (index .Skip "Label")}}
+// Label 返回显示名称，取自常量注释
+func (v {{$t}}) Label() string {
+	if label, ok := _{{$t}}Labels[v]; ok {
+		return label
+	}
+	return fmt.Sprintf("{{$t}}(%v)", {{.Base}}(v))
+}
+{{end}}
+{{- if not (index .Skip "IsValid")}}
+// IsValid 判断是否为已定义的取值
+func (v {{$t}}) IsValid() bool {
+	_, ok := _{{$t}}Names[v]
+	return ok
+}
+{{end}}
+{{- if not (index .Skip "Parse")}}
+// Parse{{$t}} 根据常量名或显示名称解析取值
+func Parse{{$t}}(s string) ({{$t}}, error) {
+	for _, v := range _{{$t}}Values {
+		if _{{$t}}Names[v] == s {
+			return v, nil
+		}
+	}
+	for _, v := range _{{$t}}Values {
+		if _{{$t}}Labels[v] == s {
+			return v, nil
+		}
+	}
+	var zero {{$t}}
+	return zero, fmt.Errorf("无效的 {{$t}}: %q", s)
+}
+{{end}}
+{{- if not (index .Skip "MarshalJSON")}}
+// MarshalJSON 按底层值序列化
+func (v {{$t}}) MarshalJSON() ([]byte, error) {
+	return json.Marshal({{.Base}}(v))
+}
+{{end}}
+{{- if not (index .Skip "UnmarshalJSON")}}
+// UnmarshalJSON 按底层值反序列化，并校验取值是否已定义
+func (v *{{$t}}) UnmarshalJSON(data []byte) error {
+	var raw {{.Base}}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("解析 {{$t}} 失败: %w", err)
+	}
+	if !{{$t}}(raw).IsValid() {
+		return fmt.Errorf("无效的 {{$t}}: %v", raw)
+	}
+	*v = {{$t}}(raw)
+	return nil
+}
+{{end}}
+{{- end}}`))
+
+// 渲染生成文件并用 gofmt 格式化，只导入实际用到的包
+func renderGoEnums(pkg string, enums []goEnum) ([]byte, error) {
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Type < enums[j].Type })
+
+	var body bytes.Buffer
+	if err := goEnumTemplate.Execute(&body, enums); err != nil {
+		return nil, err
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// %s\n\npackage %s\n\n", generatedHeader, pkg)
+	var imports []string
+	if bytes.Contains(body.Bytes(), []byte("json.")) {
+		imports = append(imports, `"encoding/json"`)
+	}
+	if bytes.Contains(body.Bytes(), []byte("fmt.")) {
+		imports = append(imports, `"fmt"`)
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&src, "import (\n%s\n)\n", strings.Join(imports, "\n"))
+	}
+	src.Write(body.Bytes())
+
+	return format.Source(src.Bytes())
+}
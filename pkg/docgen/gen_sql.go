@@ -0,0 +1,254 @@
+package docgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SQL 约束生成方式
+const (
+	SQLModeCheck = "check" // ALTER TABLE ... ADD CONSTRAINT ... CHECK (col IN (...))
+	SQLModeEnum  = "enum"  // CREATE TYPE ... AS ENUM，并把列改为该类型
+)
+
+// enumColumn 是映射到某个数据库列的枚举
+type enumColumn struct {
+	Group  *EnumGroup
+	Table  string
+	Schema string // 表的 schema，取自建表语句，未知时为空
+	Key    string // 表在 dbComments 中的键，多仓库合并后带项目前缀
+	Column string
+}
+
+// sqlEnumValue 是枚举项在 SQL 中的取值
+type sqlEnumValue struct {
+	Literal string // SQL 字面量
+	Text    string // 注释中使用的原始值
+	Label   string
+}
+
+// GenerateSQL 根据映射到数据库列的枚举生成迁移片段：CHECK 约束或枚举类型，
+// 以及按“描述：值-标签，值-标签”格式刷新的 COMMENT ON COLUMN。
+// 需要先调用 ParseEnums 和 ParseDBComments
+func (p *Parser) GenerateSQL() (map[string][]byte, error) {
+	opts := p.config.Gen.SQL
+	if opts.Dialect != "postgres" {
+		return nil, fmt.Errorf("不支持的数据库方言: %s，目前只支持 postgres", opts.Dialect)
+	}
+	if opts.Mode != SQLModeCheck && opts.Mode != SQLModeEnum {
+		return nil, fmt.Errorf("不支持的约束生成方式: %s", opts.Mode)
+	}
+
+	var sql strings.Builder
+	sql.WriteString("-- " + generatedHeader + "\n")
+
+	createdTypes := make(map[string]bool)
+	for _, ec := range p.enumColumns() {
+		values := sqlEnumValues(ec.Group)
+		if len(values) == 0 {
+			fmt.Printf("警告: %s 没有可用于 SQL 的枚举值，跳过 %s.%s\n", ec.Group.Name, ec.Table, ec.Column)
+			continue
+		}
+
+		// 使用建表语句中的 schema，未知时使用配置的默认 schema
+		schema := ec.Schema
+		if schema == "" {
+			schema = opts.Schema
+		}
+		table := quoteIdent(schema) + "." + quoteIdent(ec.Table)
+		column := quoteIdent(ec.Column)
+
+		sql.WriteString(fmt.Sprintf("\n-- %s", ec.Group.Name))
+		if ec.Group.File != "" {
+			sql.WriteString(fmt.Sprintf("（%s）", ec.Group.SourceLocation.String()))
+		}
+		sql.WriteString("\n")
+
+		mode := opts.Mode
+		if mode == SQLModeEnum && !allStringValues(ec.Group) {
+			fmt.Printf("警告: %s 不是字符串枚举，%s.%s 改为生成 CHECK 约束\n", ec.Group.Name, ec.Table, ec.Column)
+			mode = SQLModeCheck
+		}
+
+		var literals []string
+		for _, v := range values {
+			literals = append(literals, v.Literal)
+		}
+
+		switch mode {
+		case SQLModeCheck:
+			constraint := quoteIdent(ec.Table + "_" + ec.Column + "_check")
+			sql.WriteString(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;\n", table, constraint))
+			sql.WriteString(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s IN (%s));\n",
+				table, constraint, column, strings.Join(literals, ", ")))
+		case SQLModeEnum:
+			typeName := quoteIdent(schema) + "." + quoteIdent(toSnakeCase(enumTypeName(ec.Group)))
+			if !createdTypes[typeName] {
+				createdTypes[typeName] = true
+				sql.WriteString("DO $$ BEGIN\n")
+				sql.WriteString(fmt.Sprintf("    CREATE TYPE %s AS ENUM (%s);\n", typeName, strings.Join(literals, ", ")))
+				sql.WriteString("EXCEPTION\n    WHEN duplicate_object THEN NULL;\nEND $$;\n")
+			}
+			// 原默认值无法自动转换为枚举类型，先删除，改类型后再按枚举值恢复
+			sql.WriteString(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;\n", table, column))
+			sql.WriteString(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;\n",
+				table, column, typeName, column, typeName))
+			if def := p.columnDefault(ec); def != "" {
+				if text, ok := defaultText(def); ok && hasEnumValue(values, text) {
+					sql.WriteString(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s::%s;\n",
+						table, column, quoteLiteral(text), typeName))
+				} else {
+					fmt.Printf("警告: %s.%s 的默认值 %s 不是 %s 的取值，已删除默认值\n", ec.Table, ec.Column, def, ec.Group.Name)
+					sql.WriteString(fmt.Sprintf("-- 警告: 原默认值 %s 不是 %s 的取值，已删除，需要时请手动设置默认值\n",
+						oneLine(def), ec.Group.Name))
+				}
+			}
+		}
+
+		sql.WriteString(fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;\n",
+			table, column, quoteLiteral(p.columnComment(ec, values))))
+	}
+
+	return map[string][]byte{opts.FileName: []byte(sql.String())}, nil
+}
+
+// 查找枚举对应的数据库列：优先使用 @ai:column 指令，
+// 否则按类型名的 snake_case 匹配已解析表中的同名列
+func (p *Parser) enumColumns() []enumColumn {
+	var result []enumColumn
+	for _, group := range p.sortedEnums() {
+		if len(group.Columns) > 0 {
+			for _, ref := range group.Columns {
+				table, column, ok := strings.Cut(ref, ".")
+				if !ok {
+					fmt.Printf("警告: 无效的 @ai:column 参数 %q，应为 table.column\n", ref)
+					continue
+				}
+				key := qualify(group.Project, table)
+				result = append(result, enumColumn{
+					Group: group, Table: table, Schema: p.dbComments[key].Schema, Key: key, Column: column,
+				})
+			}
+			continue
+		}
+
+		// 只匹配同一项目中的表
+		column := toSnakeCase(enumTypeName(group))
+		for key, table := range p.dbComments {
+			if table.Project != group.Project {
+				continue
+			}
+			for _, field := range table.Fields {
+				if field.FieldName == column {
+					result = append(result, enumColumn{
+						Group: group, Table: table.TableName, Schema: table.Schema, Key: key, Column: column,
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Key != result[j].Key {
+			return result[i].Key < result[j].Key
+		}
+		return result[i].Column < result[j].Column
+	})
+	return result
+}
+
+func sqlEnumValues(group *EnumGroup) []sqlEnumValue {
+	var values []sqlEnumValue
+	seen := make(map[string]bool)
+	for _, item := range group.Items {
+		text, isString, ok := literalValue(item.constValue())
+		if !ok {
+			fmt.Printf("警告: 无法确定枚举值，已跳过 %s\n", item.Name)
+			continue
+		}
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+
+		literal := text
+		if isString {
+			literal = quoteLiteral(text)
+		}
+		label := oneLine(item.Comment)
+		if label == "" {
+			label = item.Name
+		}
+		values = append(values, sqlEnumValue{Literal: literal, Text: text, Label: label})
+	}
+	return values
+}
+
+func allStringValues(group *EnumGroup) bool {
+	for _, item := range group.Items {
+		if _, isString, ok := literalValue(item.constValue()); ok && !isString {
+			return false
+		}
+	}
+	return true
+}
+
+// 建表语句中列的默认值表达式，未知时为空
+func (p *Parser) columnDefault(ec enumColumn) string {
+	for _, field := range p.dbComments[ec.Key].Fields {
+		if field.FieldName == ec.Column {
+			return field.Default
+		}
+	}
+	return ""
+}
+
+// 匹配字符串默认值，可以带类型转换，如 'init'::character varying
+var defaultLiteralRegex = regexp.MustCompile(`^'((?:[^']|'')*)'(?:::.+)?$`)
+
+// 返回字符串默认值的文本，不是字符串字面量时返回 false
+func defaultText(def string) (string, bool) {
+	m := defaultLiteralRegex.FindStringSubmatch(strings.TrimSpace(def))
+	if m == nil {
+		return "", false
+	}
+	return strings.ReplaceAll(m[1], "''", "'"), true
+}
+
+func hasEnumValue(values []sqlEnumValue, text string) bool {
+	for _, v := range values {
+		if v.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// 列注释沿用现有注释“：”前的描述，没有时使用枚举组描述
+func (p *Parser) columnComment(ec enumColumn, values []sqlEnumValue) string {
+	desc := oneLine(ec.Group.Description)
+	for _, field := range p.dbComments[ec.Key].Fields {
+		if field.FieldName == ec.Column && field.Comment != "" {
+			desc = field.Comment
+			if i := strings.IndexAny(desc, "：:"); i >= 0 {
+				desc = desc[:i]
+			}
+		}
+	}
+
+	var parts []string
+	for _, v := range values {
+		parts = append(parts, v.Text+"-"+v.Label)
+	}
+	return strings.TrimSpace(desc) + "：" + strings.Join(parts, "，")
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
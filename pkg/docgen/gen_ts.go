@@ -0,0 +1,157 @@
+package docgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TypeScript 输出布局和风格
+const (
+	TSLayoutPackage = "package" // 每个包一个 <package>.ts 文件
+	TSLayoutSingle  = "single"  // 全部写入一个文件
+
+	TSStyleEnum  = "enum"  // export enum
+	TSStyleConst = "const" // export const Xxx = {...} as const
+)
+
+// GenerateTS 把枚举组生成为 TypeScript 定义，返回文件名到内容的映射。
+// 每个枚举同时生成 XxxLabel 映射，取值为枚举项注释
+func (p *Parser) GenerateTS() (map[string][]byte, error) {
+	opts := p.config.Gen.TS
+	switch opts.Style {
+	case TSStyleEnum, TSStyleConst:
+	default:
+		return nil, fmt.Errorf("不支持的 TypeScript 风格: %s", opts.Style)
+	}
+
+	files := make(map[string][]byte)
+	pkgs, byPkg := groupsByPackage(p.sortedEnums())
+
+	switch opts.Layout {
+	case TSLayoutPackage:
+		for _, pkg := range pkgs {
+			files[pkg+".ts"] = []byte(p.renderTS(byPkg[pkg], opts.Style, false))
+		}
+	case TSLayoutSingle:
+		var groups []*EnumGroup
+		for _, pkg := range pkgs {
+			groups = append(groups, byPkg[pkg]...)
+		}
+		files[opts.FileName] = []byte(p.renderTS(groups, opts.Style, true))
+	default:
+		return nil, fmt.Errorf("不支持的 TypeScript 布局: %s", opts.Layout)
+	}
+
+	return files, nil
+}
+
+// 渲染一个 .ts 文件，qualify 为真时类型名冲突的枚举加上包名前缀
+func (p *Parser) renderTS(groups []*EnumGroup, style string, qualify bool) string {
+	var ts strings.Builder
+	ts.WriteString("// " + generatedHeader + "\n")
+
+	used := make(map[string]bool)
+	for _, group := range groups {
+		typeName := enumTypeName(group)
+		if used[typeName] && qualify {
+			typeName = exportedName(group.Package) + typeName
+		}
+		if used[typeName] {
+			fmt.Printf("警告: TypeScript 枚举名重复，已跳过 %s (%s)\n", typeName, group.Name)
+			continue
+		}
+		used[typeName] = true
+
+		ts.WriteString("\n")
+		source := ""
+		if group.File != "" {
+			source = "来源：" + group.SourceLocation.String()
+		}
+		writeTSDoc(&ts, "", group.Description, source)
+
+		type member struct {
+			name, value, label string
+		}
+		var members []member
+		for _, item := range group.Items {
+			value, ok := tsValue(item.constValue())
+			if !ok {
+				fmt.Printf("警告: 无法确定枚举值，已跳过 %s.%s\n", typeName, item.Name)
+				continue
+			}
+			label := oneLine(item.Comment)
+			if label == "" {
+				label = item.Name
+			}
+			members = append(members, member{enumMemberName(typeName, item.Name), value, label})
+		}
+
+		if style == TSStyleEnum {
+			ts.WriteString(fmt.Sprintf("export enum %s {\n", typeName))
+		} else {
+			ts.WriteString(fmt.Sprintf("export const %s = {\n", typeName))
+		}
+		for _, m := range members {
+			writeTSDoc(&ts, "  ", m.label)
+			if style == TSStyleEnum {
+				ts.WriteString(fmt.Sprintf("  %s = %s,\n", m.name, m.value))
+			} else {
+				ts.WriteString(fmt.Sprintf("  %s: %s,\n", m.name, m.value))
+			}
+		}
+		if style == TSStyleEnum {
+			ts.WriteString("}\n\n")
+		} else {
+			ts.WriteString("} as const;\n\n")
+			ts.WriteString(fmt.Sprintf("export type %s = (typeof %s)[keyof typeof %s];\n\n", typeName, typeName, typeName))
+		}
+
+		// 标签映射，同值的枚举项只保留第一个
+		ts.WriteString(fmt.Sprintf("export const %sLabel: Record<%s, string> = {\n", typeName, typeName))
+		seen := make(map[string]bool)
+		for _, m := range members {
+			if seen[m.value] {
+				continue
+			}
+			seen[m.value] = true
+			ts.WriteString(fmt.Sprintf("  [%s.%s]: %s,\n", typeName, m.name, strconv.Quote(m.label)))
+		}
+		ts.WriteString("};\n")
+	}
+
+	return ts.String()
+}
+
+// 写入 JSDoc 注释，每个非空参数一行
+func writeTSDoc(ts *strings.Builder, indent string, lines ...string) {
+	var text []string
+	for _, line := range lines {
+		if line = oneLine(line); line != "" {
+			text = append(text, strings.ReplaceAll(line, "*/", "* /"))
+		}
+	}
+	switch len(text) {
+	case 0:
+	case 1:
+		ts.WriteString(fmt.Sprintf("%s/** %s */\n", indent, text[0]))
+	default:
+		ts.WriteString(indent + "/**\n")
+		for _, line := range text {
+			ts.WriteString(fmt.Sprintf("%s * %s\n", indent, line))
+		}
+		ts.WriteString(indent + " */\n")
+	}
+}
+
+// 把枚举值转换为 TypeScript 字面量，只支持字符串和数字
+func tsValue(value interface{}) (string, bool) {
+	text, isString, ok := literalValue(value)
+	if !ok {
+		return "", false
+	}
+	if isString {
+		return strconv.Quote(text), true
+	}
+	return text, true
+}
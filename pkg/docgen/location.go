@@ -0,0 +1,125 @@
+package docgen
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SourceLocation 表示实体在源码中的位置
+type SourceLocation struct {
+	File      string `json:"file"`                // 文件路径（相对仓库根目录）
+	StartLine int    `json:"start_line"`          // 起始行
+	EndLine   int    `json:"end_line"`            // 结束行
+	Permalink string `json:"permalink,omitempty"` // 源码永久链接
+}
+
+// String 返回 file:start-end 形式的位置描述
+func (l SourceLocation) String() string {
+	switch {
+	case l.File == "":
+		return ""
+	case l.StartLine == 0:
+		return l.File
+	case l.EndLine <= l.StartLine:
+		return fmt.Sprintf("%s:%d", l.File, l.StartLine)
+	default:
+		return fmt.Sprintf("%s:%d-%d", l.File, l.StartLine, l.EndLine)
+	}
+}
+
+// Markdown 返回位置的 Markdown 表示，有永久链接时渲染为链接
+func (l SourceLocation) Markdown() string {
+	if l.File == "" {
+		return ""
+	}
+	if l.Permalink != "" {
+		return fmt.Sprintf("[`%s`](%s)", l.String(), l.Permalink)
+	}
+	return fmt.Sprintf("`%s`", l.String())
+}
+
+// 构造源码位置，并按配置生成永久链接
+func (p *Parser) location(rootPath, filename string, startLine, endLine int) SourceLocation {
+	loc := SourceLocation{
+		File:      p.relPath(rootPath, filename),
+		StartLine: startLine,
+		EndLine:   endLine,
+	}
+	loc.Permalink = p.permalink(loc)
+	return loc
+}
+
+func (p *Parser) permalink(loc SourceLocation) string {
+	pattern := p.config.Permalink.URLPattern
+	if pattern == "" {
+		return ""
+	}
+	end := loc.EndLine
+	if end < loc.StartLine {
+		end = loc.StartLine
+	}
+	return strings.NewReplacer(
+		"{commit}", p.config.Permalink.Commit,
+		"{path}", loc.File,
+		"{start}", strconv.Itoa(loc.StartLine),
+		"{end}", strconv.Itoa(end),
+	).Replace(pattern)
+}
+
+// 计算相对仓库根目录的路径，统一使用 / 分隔
+func (p *Parser) relPath(rootPath, filename string) string {
+	base := rootPath
+	if p.config.RepoRoot != "" {
+		base = p.config.RepoRoot
+	}
+	absBase, err1 := filepath.Abs(base)
+	absFile, err2 := filepath.Abs(filename)
+	if err1 != nil || err2 != nil {
+		return filepath.ToSlash(filename)
+	}
+	rel, err := filepath.Rel(absBase, absFile)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// GitTopLevel 返回目录所在 git 仓库的根目录
+func GitTopLevel(dir string) (string, error) {
+	return gitOutput(dir, "rev-parse", "--show-toplevel")
+}
+
+// GitCommit 返回指定引用对应的提交哈希，ref 为空时使用 HEAD
+func GitCommit(dir, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return gitOutput(dir, "rev-parse", ref)
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("执行 git %s 失败: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// 枚举项与所在组同文件时只显示行号，否则显示完整位置
+func itemLocation(groupFile string, l SourceLocation) string {
+	if l.StartLine == 0 {
+		return "-"
+	}
+	text := fmt.Sprintf("L%d", l.StartLine)
+	if l.File != groupFile {
+		text = fmt.Sprintf("%s:%d", l.File, l.StartLine)
+	}
+	if l.Permalink != "" {
+		return fmt.Sprintf("[%s](%s)", text, l.Permalink)
+	}
+	return text
+}
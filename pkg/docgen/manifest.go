@@ -0,0 +1,273 @@
+package docgen
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest 多仓库生成清单，通常为 docgen.yaml
+type Manifest struct {
+	Output   string          `yaml:"output"`   // 输出目录，相对清单文件
+	Formats  []string        `yaml:"formats"`  // 输出格式，默认为 markdown
+	Parallel int             `yaml:"parallel"` // 同时处理的项目数，默认为 4
+	Combined string          `yaml:"combined"` // 合并目录的输出名称，默认为 all
+	Projects []ProjectConfig `yaml:"projects"`
+}
+
+// ProjectConfig 清单中的一个项目
+type ProjectConfig struct {
+	Name       string   `yaml:"name"`       // 项目名，用作实体的命名空间，默认为目录名
+	Path       string   `yaml:"path"`       // 本地仓库路径，相对清单文件
+	Ref        string   `yaml:"ref"`        // git 提交、分支或标签，设置后解析该版本的源码
+	Include    []string `yaml:"include"`    // 只解析匹配的文件
+	Exclude    []string `yaml:"exclude"`    // 跳过匹配的文件
+	Extractors []string `yaml:"extractors"` // 启用的提取器，默认全部
+	Output     string   `yaml:"output"`     // 项目输出名称，默认为项目名
+	Dialect    string   `yaml:"dialect"`    // 数据库方言
+	RemoteURL  string   `yaml:"remote_url"` // 源码链接模板
+}
+
+// LoadManifest 加载清单文件，相对路径按清单所在目录解析
+func LoadManifest(filePath string) (*Manifest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取清单文件失败: %w", err)
+	}
+
+	manifest := &Manifest{Output: "docs", Parallel: 4, Combined: "all"}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("解析清单文件失败: %w", err)
+	}
+	if len(manifest.Formats) == 0 {
+		manifest.Formats = []string{"markdown"}
+	}
+	if manifest.Parallel <= 0 {
+		manifest.Parallel = 1
+	}
+
+	baseDir := filepath.Dir(filePath)
+	if !filepath.IsAbs(manifest.Output) {
+		manifest.Output = filepath.Join(baseDir, manifest.Output)
+	}
+
+	names := make(map[string]bool)
+	for i := range manifest.Projects {
+		project := &manifest.Projects[i]
+		if project.Path == "" {
+			return nil, fmt.Errorf("第 %d 个项目缺少 path", i+1)
+		}
+		if !filepath.IsAbs(project.Path) {
+			project.Path = filepath.Join(baseDir, project.Path)
+		}
+		if project.Name == "" {
+			project.Name = filepath.Base(project.Path)
+		}
+		if project.Output == "" {
+			project.Output = project.Name
+		}
+		if names[project.Name] {
+			return nil, fmt.Errorf("项目名重复: %s", project.Name)
+		}
+		names[project.Name] = true
+		for _, name := range project.Extractors {
+			if !isExtractor(name) {
+				return nil, fmt.Errorf("项目 %s 使用了未知的提取器: %s", project.Name, name)
+			}
+		}
+	}
+
+	return manifest, nil
+}
+
+// Merge 把另一个解析器的结果以 project/ 为命名空间并入当前解析器，
+// 不同项目的同名枚举、数据表不会互相覆盖
+func (p *Parser) Merge(project string, other *Parser) {
+	for name, group := range other.enums {
+		group.Project = project
+		key := qualify(project, name)
+		if existing, ok := p.enums[key]; ok {
+			p.mergeEnumGroup(existing, group)
+		} else {
+			p.enums[key] = group
+		}
+	}
+	for name, table := range other.dbComments {
+		table.Project = project
+		p.dbComments[qualify(project, name)] = table
+	}
+	for name, schema := range other.apiSchemas {
+		schema.Project = project
+		p.apiSchemas[qualify(project, name)] = schema
+	}
+
+	// 错误码、路由和配置项以包名区分项目
+	for _, code := range other.errorCodes {
+		code.Package = qualify(project, code.Package)
+		p.errorCodes = append(p.errorCodes, code)
+	}
+	for _, route := range other.routes {
+		route.Package = qualify(project, route.Package)
+		p.routes = append(p.routes, route)
+	}
+	p.sortRoutes()
+	for _, cs := range other.configStructs {
+		cs.Package = qualify(project, cs.Package)
+		p.configStructs = append(p.configStructs, cs)
+	}
+
+	p.usagesScanned = p.usagesScanned || other.usagesScanned
+}
+
+// 带项目命名空间的名称
+func qualify(project, name string) string {
+	if project == "" {
+		return name
+	}
+	return project + "/" + name
+}
+
+// 判断文件是否满足 include/exclude 配置，模式匹配相对解析目录的路径
+func (p *Parser) included(rootPath, filename string) bool {
+	if len(p.config.Include) == 0 && len(p.config.Exclude) == 0 {
+		return true
+	}
+	rel, err := filepath.Rel(rootPath, filename)
+	if err != nil {
+		return true
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, pattern := range p.config.Exclude {
+		if matchGlob(pattern, rel) {
+			return false
+		}
+	}
+	if len(p.config.Include) == 0 {
+		return true
+	}
+	for _, pattern := range p.config.Include {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// 匹配 glob，** 可以跨越多级目录；不含 / 的模式匹配文件名，如 *_test.go
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				// **/ 匹配零到多级目录
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					re.WriteString("(?:.*/)?")
+				} else {
+					re.WriteString(".*")
+				}
+			} else {
+				re.WriteString("[^/]*")
+			}
+		case '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+
+	ok, _ := regexp.MatchString(re.String(), name)
+	return ok
+}
+
+// ExportGitRef 把仓库指定版本的源码导出到临时目录，返回目录和清理函数
+func ExportGitRef(repoPath, ref string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "docgen-")
+	if err != nil {
+		return "", nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	cmd := exec.Command("git", "-C", repoPath, "archive", "--format=tar", ref)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("执行 git archive 失败: %w", err)
+	}
+
+	extractErr := extractTar(stdout, dir)
+	// 读完剩余输出，避免 git 阻塞在写管道上
+	io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("导出 %s@%s 失败: %s", repoPath, ref, strings.TrimSpace(stderr.String()))
+	}
+	if extractErr != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("解压源码失败: %w", extractErr)
+	}
+
+	return dir, cleanup, nil
+}
+
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("非法的文件路径: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
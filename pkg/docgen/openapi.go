@@ -0,0 +1,375 @@
+package docgen
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPI 中的请求方法
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPISpec 表示一个已解析的 OpenAPI 3 或 Swagger 2 文档
+type openAPISpec struct {
+	root     *yaml.Node
+	title    string
+	basePath string
+	rootPath string
+	filename string
+}
+
+// ParseOpenAPI 解析 OpenAPI 3 / Swagger 2 文档：schema 中的枚举转换为枚举组，
+// 接口操作转换为路由，组件 schema 转换为字段表
+func (p *Parser) ParseOpenAPI(rootPath string) (map[string]TableComment, error) {
+	err := p.walkFiles(rootPath, "", func(path string) error {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		spec, err := loadOpenAPISpec(rootPath, path)
+		if err != nil {
+			fmt.Printf("警告: 解析 OpenAPI 文档出错 (文件: %s): %v\n", path, err)
+			return nil
+		}
+		if spec == nil {
+			return nil
+		}
+
+		p.parseOpenAPIRoutes(spec)
+		p.parseOpenAPISchemas(spec)
+		return nil
+	})
+
+	p.sortRoutes()
+	return p.apiSchemas, err
+}
+
+// 读取文件并判断是否为 OpenAPI 文档，不是时返回 nil
+func loadOpenAPISpec(rootPath, filename string) (*openAPISpec, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// 非 OpenAPI 文件的解析错误不影响生成
+		if isOpenAPIFileName(filename) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if mapValue(root, "openapi") == nil && mapValue(root, "swagger") == nil {
+		return nil, nil
+	}
+
+	spec := &openAPISpec{
+		root:     root,
+		title:    scalarValue(mapValue(mapValue(root, "info"), "title")),
+		rootPath: rootPath,
+		filename: filename,
+	}
+	if spec.title == "" {
+		spec.title = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+
+	// Swagger 2 使用 basePath，OpenAPI 3 取第一个 server 地址的路径部分
+	spec.basePath = scalarValue(mapValue(root, "basePath"))
+	if servers := mapValue(root, "servers"); servers != nil && len(servers.Content) > 0 {
+		if u, err := url.Parse(scalarValue(mapValue(servers.Content[0], "url"))); err == nil {
+			spec.basePath = u.Path
+		}
+	}
+	spec.basePath = strings.TrimSuffix(spec.basePath, "/")
+
+	return spec, nil
+}
+
+func isOpenAPIFileName(filename string) bool {
+	name := strings.ToLower(filepath.Base(filename))
+	return strings.Contains(name, "openapi") || strings.Contains(name, "swagger")
+}
+
+func (p *Parser) parseOpenAPIRoutes(spec *openAPISpec) {
+	paths := mapValue(spec.root, "paths")
+	if paths == nil {
+		return
+	}
+
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		routePath := paths.Content[i].Value
+		item := paths.Content[i+1]
+		for _, method := range openAPIMethods {
+			op := mapValue(item, method)
+			if op == nil {
+				continue
+			}
+
+			summary := scalarValue(mapValue(op, "summary"))
+			description := scalarValue(mapValue(op, "description"))
+			doc := summary
+			if description != "" && description != summary {
+				doc = strings.TrimSpace(summary + " " + description)
+			}
+
+			p.routes = append(p.routes, Route{
+				Method:         strings.ToUpper(method),
+				Path:           joinRoutePath(spec.basePath, routePath),
+				Handler:        orDash(scalarValue(mapValue(op, "operationId"))),
+				Doc:            strings.Join(strings.Fields(doc), " "),
+				Package:        spec.title,
+				SourceLocation: p.location(spec.rootPath, spec.filename, op.Line, lastLine(op)),
+			})
+		}
+	}
+}
+
+func (p *Parser) parseOpenAPISchemas(spec *openAPISpec) {
+	schemas := mapValue(mapValue(spec.root, "components"), "schemas")
+	if schemas == nil {
+		schemas = mapValue(spec.root, "definitions")
+	}
+	if schemas == nil {
+		return
+	}
+
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		name := schemas.Content[i].Value
+		schema := schemas.Content[i+1]
+		description := oneLine(scalarValue(mapValue(schema, "description")))
+
+		if enum := mapValue(schema, "enum"); enum != nil {
+			p.addOpenAPIEnum(spec, name, description, schema)
+			continue
+		}
+
+		properties := schemaProperties(schema)
+		if len(properties) == 0 {
+			continue
+		}
+
+		table := TableComment{
+			TableName:      name,
+			Schema:         spec.title,
+			Comment:        description,
+			SourceLocation: p.location(spec.rootPath, spec.filename, schemas.Content[i].Line, lastLine(schema)),
+		}
+		for j := 0; j+1 < len(properties); j += 2 {
+			propName := properties[j].Value
+			prop := properties[j+1]
+			comment := oneLine(scalarValue(mapValue(prop, "description")))
+
+			// 属性上的内联枚举单独生成枚举组
+			if mapValue(prop, "enum") != nil {
+				enumName := name + exportedName(propName)
+				p.addOpenAPIEnum(spec, enumName, comment, prop)
+				comment = strings.TrimSpace(comment + " 取值见枚举 " + enumName)
+			}
+
+			table.Fields = append(table.Fields, FieldComment{
+				FieldName: propName,
+				FieldType: schemaType(prop),
+				Comment:   comment,
+			})
+		}
+
+		key := name
+		if _, exists := p.apiSchemas[key]; exists {
+			key = spec.title + "." + name
+		}
+		p.apiSchemas[key] = table
+	}
+}
+
+// OpenAPI 枚举组的类型
+const openAPIEnumType = "openapi"
+
+// 把 schema 中的 enum 转换为枚举组，支持 x-enum-varnames 和 x-enum-descriptions 扩展
+func (p *Parser) addOpenAPIEnum(spec *openAPISpec, name, description string, schema *yaml.Node) {
+	enum := mapValue(schema, "enum")
+	varNames := mapValue(schema, "x-enum-varnames")
+	if varNames == nil {
+		varNames = mapValue(schema, "x-enumNames")
+	}
+	descriptions := mapValue(schema, "x-enum-descriptions")
+
+	group := &EnumGroup{
+		Name:           strings.TrimSpace(name + " " + description),
+		Description:    description,
+		Package:        spec.title,
+		Type:           openAPIEnumType,
+		SourceLocation: p.location(spec.rootPath, spec.filename, schema.Line, lastLine(schema)),
+	}
+
+	for i, value := range enum.Content {
+		item := EnumItem{
+			Name:           value.Value,
+			Value:          enumNodeValue(value),
+			SourceLocation: p.location(spec.rootPath, spec.filename, value.Line, value.Line),
+		}
+		if varNames != nil && i < len(varNames.Content) {
+			item.Name = varNames.Content[i].Value
+		}
+		if descriptions != nil {
+			switch descriptions.Kind {
+			case yaml.SequenceNode:
+				if i < len(descriptions.Content) {
+					item.Comment = descriptions.Content[i].Value
+				}
+			case yaml.MappingNode:
+				item.Comment = scalarValue(mapValue(descriptions, value.Value))
+			}
+		}
+		group.Items = append(group.Items, item)
+	}
+
+	if len(group.Items) > 0 {
+		p.addEnumGroup(group)
+	}
+}
+
+// 字符串枚举值与 Go 字面量保持一致，带引号
+func enumNodeValue(node *yaml.Node) interface{} {
+	if node.Tag == "!!str" {
+		return strconv.Quote(node.Value)
+	}
+	return node.Value
+}
+
+// 收集 schema 的属性，合并 allOf 中的内联属性
+func schemaProperties(schema *yaml.Node) []*yaml.Node {
+	var result []*yaml.Node
+	if props := mapValue(schema, "properties"); props != nil {
+		result = append(result, props.Content...)
+	}
+	if allOf := mapValue(schema, "allOf"); allOf != nil {
+		for _, member := range allOf.Content {
+			result = append(result, schemaProperties(member)...)
+		}
+	}
+	return result
+}
+
+// 返回 schema 的类型描述，如 string(date-time)、[]Order
+func schemaType(schema *yaml.Node) string {
+	if ref := scalarValue(mapValue(schema, "$ref")); ref != "" {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	typ := scalarValue(mapValue(schema, "type"))
+	if typ == "array" {
+		return "[]" + schemaType(mapValue(schema, "items"))
+	}
+	if format := scalarValue(mapValue(schema, "format")); format != "" {
+		return fmt.Sprintf("%s(%s)", typ, format)
+	}
+	if typ == "" {
+		return "object"
+	}
+	return typ
+}
+
+// 获取映射节点中指定键的值
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// 返回节点子树的最后一行
+func lastLine(node *yaml.Node) int {
+	line := node.Line
+	for _, child := range node.Content {
+		if l := lastLine(child); l > line {
+			line = l
+		}
+	}
+	return line
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// 把 snake_case 或 camelCase 属性名转换为导出的驼峰名
+func exportedName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if r == '_' || r == '-' || r == ' ' {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// 生成接口数据模型文档
+func (p *Parser) writeAPISchemas(md *strings.Builder) {
+	if len(p.apiSchemas) == 0 {
+		return
+	}
+
+	writeRAGMeta(md, map[string]string{"doc_type": "api_schema"})
+	md.WriteString("# 接口数据模型\n\n")
+	var names []string
+	for name := range p.apiSchemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		schema := p.apiSchemas[name]
+		writeRAGMeta(md, map[string]string{
+			"doc_type": "api_schema",
+			"project":  schema.Project,
+			"schema":   schema.Schema,
+			"name":     name,
+		})
+		if schema.Comment != "" {
+			md.WriteString(fmt.Sprintf("## %s（%s）\n\n", name, schema.Comment))
+		} else {
+			md.WriteString(fmt.Sprintf("## %s\n\n", name))
+		}
+		if schema.File != "" {
+			md.WriteString(fmt.Sprintf("**来源：** %s\n\n", schema.SourceLocation.Markdown()))
+		}
+
+		md.WriteString("| 字段 | 类型 | 描述 |\n|---|---|---|\n")
+		for _, field := range schema.Fields {
+			md.WriteString(fmt.Sprintf("| %s | %s | %s |\n",
+				field.FieldName, field.FieldType, orDash(field.Comment)))
+		}
+		md.WriteString("\n")
+	}
+}
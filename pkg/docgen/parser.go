@@ -1,39 +1,43 @@
 package docgen
 
 import (
+	"encoding/json"
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/parser"
 	"go/token"
 	"io/ioutil"
 	"os"
-	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
-	"unicode"
 
 	"bytes"
 
 	"unicode/utf8"
 
+	"enum_tools/pkg/segment"
+
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/encoding/traditionalchinese"
 	"golang.org/x/text/transform"
 )
 
-const defaultGitPath = "." // 默认为当前目录
-
 // EnumGroup 表示一个枚举分组
 type EnumGroup struct {
-	Name        string     `json:"name"`        // 枚举组名称
-	Description string     `json:"description"` // 枚举组描述
-	Package     string     `json:"package"`     // 包路径
-	File        string     `json:"file"`        // 文件路径
-	Type        string     `json:"type"`        // 类型（const/var/type）
-	Items       []EnumItem `json:"items"`       // 枚举项
-	Tags        []string   `json:"tags"`        // 相关标签，用于搜索
-	Category    string     `json:"category"`    // 分类（如：状态、类型、标志等）
+	Name          string     `json:"name"`           // 枚举组名称
+	Description   string     `json:"description"`    // 枚举组描述
+	Package       string     `json:"package"`        // 包路径
+	Type          string     `json:"type"`           // 类型（const/var/type）
+	Items         []EnumItem `json:"items"`          // 枚举项
+	Tags          []string   `json:"tags"`           // 相关标签，用于搜索
+	Category      string     `json:"category"`       // 分类（如：状态、类型、标志等）
+	Columns       []string   `json:"columns"`        // 对应的数据库列，table.column
+	Project       string     `json:"project"`        // 所属项目，多仓库生成时设置
+	UsagesScanned bool       `json:"usages_scanned"` // 是否扫描过引用，OpenAPI 枚举等未扫描的没有引用次数
+	SourceLocation
 }
 
 // EnumItem 表示具体的枚举项
@@ -43,112 +47,145 @@ type EnumItem struct {
 	Comment     string      `json:"comment"`     // 注释说明
 	Description string      `json:"description"` // 详细描述
 	Example     string      `json:"example"`     // 使用示例
+	Usages      []EnumUsage `json:"usages"`      // 代表性引用位置
+	UsageCount  int         `json:"usage_count"` // 引用次数
+	Unused      bool        `json:"unused"`      // 是否未被引用
+	GoType      string      `json:"go_type"`     // 声明的 Go 类型，无类型常量为空
+	ConstValue  string      `json:"const_value"` // 常量求值结果（Go 字面量写法），用于生成代码，无法求值时为空
+	SourceLocation
+}
+
+// 生成代码使用的取值：优先使用常量求值结果，否则使用源码中的写法
+func (item EnumItem) constValue() interface{} {
+	if item.ConstValue != "" {
+		return item.ConstValue
+	}
+	return item.Value
 }
 
 type TableComment struct {
 	TableName string
+	Schema    string // 数据库 schema，接口数据模型为所属文档标题
+	Project   string // 所属项目，多仓库生成时设置
 	Comment   string
 	Fields    []FieldComment
+	SourceLocation
 }
 
 type FieldComment struct {
 	FieldName string
 	FieldType string // 添加字段类型
 	Comment   string
+	Default   string // 建表语句中的默认值表达式，如 ''::character varying
 }
 
 type Parser struct {
-	enums      map[string]*EnumGroup
-	dbComments map[string]TableComment
+	config        *Config
+	fset          *token.FileSet
+	enums         map[string]*EnumGroup
+	dbComments    map[string]TableComment
+	apiSchemas    map[string]TableComment
+	errorCodes    []ErrorCode
+	routes        []Route
+	configStructs []ConfigStruct
+	usagesScanned bool
+
+	// 按修改时间缓存的语法树，多个提取器和监听模式下重复解析时复用
+	astCache map[string]cachedAST
+
+	segmenter     *segment.Segmenter
+	categoryRules []categoryRule
 }
 
 func NewParser() *Parser {
+	return NewParserWithConfig(DefaultConfig())
+}
+
+// NewParserWithConfig 使用指定配置创建解析器
+func NewParserWithConfig(config *Config) *Parser {
+	rules, err := compileCategoryRules(config.Category)
+	if err != nil {
+		fmt.Printf("警告: %v\n", err)
+	}
 	return &Parser{
-		enums:      make(map[string]*EnumGroup),
-		dbComments: make(map[string]TableComment),
+		config:        config,
+		categoryRules: rules,
+		fset:          token.NewFileSet(),
+		enums:         make(map[string]*EnumGroup),
+		dbComments:    make(map[string]TableComment),
+		apiSchemas:    make(map[string]TableComment),
+		astCache:      make(map[string]cachedAST),
+		segmenter:     newSegmenter(config.Segment),
 	}
 }
 
-func (p *Parser) ParseEnums(rootPath string) (map[string]*EnumGroup, error) {
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+// 按配置创建分词器，未配置自定义词典时使用共享的默认分词器
+func newSegmenter(cfg SegmentConfig) *segment.Segmenter {
+	if len(cfg.DictFiles) == 0 && len(cfg.StopwordFiles) == 0 && len(cfg.Words) == 0 && len(cfg.Stopwords) == 0 {
+		return segment.Default()
+	}
 
-		if !info.IsDir() {
-			if err := p.parseFile(path); err != nil {
-				return err
-			}
+	seg := segment.New()
+	for _, file := range cfg.DictFiles {
+		if err := seg.LoadDictFile(file); err != nil {
+			fmt.Printf("警告: %v\n", err)
 		}
-		return nil
+	}
+	for _, file := range cfg.StopwordFiles {
+		if err := seg.LoadStopwordsFile(file); err != nil {
+			fmt.Printf("警告: %v\n", err)
+		}
+	}
+	for _, word := range cfg.Words {
+		seg.AddWord(word, 0)
+	}
+	for _, word := range cfg.Stopwords {
+		seg.AddStopword(word)
+	}
+	return seg
+}
+
+func (p *Parser) ParseEnums(rootPath string) (map[string]*EnumGroup, error) {
+	err := p.walkFiles(rootPath, ".go", func(path string) error {
+		return p.parseFile(rootPath, path)
 	})
 
 	return p.enums, err
 }
 
 func (p *Parser) ParseDBComments(rootPath string) (map[string]TableComment, error) {
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !info.IsDir() && strings.HasSuffix(path, ".sql") {
-			if err := p.parseSQLFile(path); err != nil {
-				return err
-			}
-		}
-		return nil
+	err := p.walkFiles(rootPath, ".sql", func(path string) error {
+		return p.parseSQLFile(rootPath, path)
 	})
 
 	return p.dbComments, err
 }
 
-func (p *Parser) parseFile(filename string) error {
+func (p *Parser) parseFile(rootPath, filename string) error {
 	if !strings.HasSuffix(filename, ".go") {
 		return nil
 	}
 
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	node, err := p.parseGoFile(filename)
 	if err != nil {
 		return err
 	}
 
-	// 获取相对路径
-	relPath, err := filepath.Rel(defaultGitPath, filename)
-	if err != nil {
-		relPath = filename
-	}
-
 	// 遍历所有声明
+	var types map[string]constant.Kind
 	for _, decl := range node.Decls {
 		if gen, ok := decl.(*ast.GenDecl); ok {
 			switch gen.Tok {
 			case token.CONST, token.VAR:
 				// 检查前面的注释是否包含 @ai 标签
-				if gen.Doc != nil {
-					for _, comment := range gen.Doc.List {
-						if strings.Contains(comment.Text, "@ai") {
-							// 提取注释内容（去掉 @ai 标记）
-							content := strings.TrimSpace(strings.Replace(comment.Text, "// @ai", "", 1))
-
-							// 解析声明组
-							group := p.parseEnumGroup(gen, content, node.Name.Name, relPath)
-							if group != nil {
-								// 生成标签
-								group.Tags = p.generateTags(group)
-								// 推断分类
-								group.Category = p.inferCategory(group)
-
-								// 合并或添加到现有组
-								if existing, ok := p.enums[group.Name]; ok {
-									p.mergeEnumGroup(existing, group)
-								} else {
-									p.enums[group.Name] = group
-								}
-							}
-							break // 找到 @ai 标签后就跳出
-						}
+				if content, ok := aiAnnotation(gen); ok {
+					if types == nil {
+						types = p.constTypes(node)
+					}
+					// 解析声明组
+					group := p.parseEnumGroup(gen, content, node.Name.Name, rootPath, filename, types)
+					if group != nil {
+						p.addEnumGroup(group)
 					}
 				}
 			}
@@ -158,20 +195,87 @@ func (p *Parser) parseFile(filename string) error {
 	return nil
 }
 
-func (p *Parser) parseEnumGroup(gen *ast.GenDecl, docComment, pkgName, filePath string) *EnumGroup {
+// 查找声明前的 @ai 标签，返回去掉标记后的注释内容，@ai:xxx 形式的指令不算
+func aiAnnotation(gen *ast.GenDecl) (string, bool) {
+	if gen.Doc == nil {
+		return "", false
+	}
+	for _, comment := range gen.Doc.List {
+		if strings.Contains(comment.Text, "@ai") && !strings.Contains(comment.Text, "@ai:") {
+			return strings.TrimSpace(strings.Replace(comment.Text, "// @ai", "", 1)), true
+		}
+	}
+	return "", false
+}
+
+// 读取 @ai:name 指令的参数，如 // @ai:column order_details.order_status
+func aiDirective(doc *ast.CommentGroup, name string) []string {
+	if doc == nil {
+		return nil
+	}
+	var args []string
+	prefix := "@ai:" + name
+	for _, comment := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if rest, ok := strings.CutPrefix(text, prefix); ok && (rest == "" || rest[0] == ' ') {
+			args = append(args, strings.Fields(rest)...)
+		}
+	}
+	return args
+}
+
+// 生成标签、推断分类后，合并或添加到现有组
+func (p *Parser) addEnumGroup(group *EnumGroup) {
+	group.Tags = p.generateTags(group)
+	group.Category = p.inferCategory(group)
+
+	// OpenAPI 枚举与同名的 Go 枚举分开保存，各自保留来源位置和引用统计
+	key := group.Name
+	if existing, ok := p.enums[key]; ok && (existing.Type == openAPIEnumType) != (group.Type == openAPIEnumType) {
+		key += "@" + group.File
+	}
+	if existing, ok := p.enums[key]; ok {
+		p.mergeEnumGroup(existing, group)
+	} else {
+		p.enums[key] = group
+	}
+}
+
+// 解析 Go 文件，文件未修改时直接返回缓存的语法树
+func (p *Parser) parseGoFile(filename string) (*ast.File, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := p.astCache[filename]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.file, nil
+	}
+	p.forget(filename)
+
+	node, err := parser.ParseFile(p.fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	p.astCache[filename] = cachedAST{file: node, modTime: info.ModTime(), size: info.Size()}
+	return node, nil
+}
+
+func (p *Parser) parseEnumGroup(gen *ast.GenDecl, docComment, pkgName, rootPath, filename string, types map[string]constant.Kind) *EnumGroup {
 	group := &EnumGroup{
 		Package:     pkgName,
-		File:        filePath,
 		Type:        gen.Tok.String(),
 		Description: docComment,
 		Items:       make([]EnumItem, 0),
+		SourceLocation: p.location(rootPath, filename,
+			p.fset.Position(gen.Pos()).Line, p.fset.Position(gen.End()).Line),
 	}
 
 	// 设置组名
 	group.Name = fmt.Sprintf("%s %s", p.getEnumGroupName(gen), docComment)
+	group.Columns = aiDirective(gen.Doc, "column")
 
 	// 解析枚举项
-	items := p.parseEnumItems(gen, docComment)
+	items := p.parseEnumItems(gen, docComment, rootPath, filename, types)
 	if len(items) > 0 {
 		group.Items = items
 		return group
@@ -180,32 +284,20 @@ func (p *Parser) parseEnumGroup(gen *ast.GenDecl, docComment, pkgName, filePath
 	return nil
 }
 
-// 生成搜索标签
+// 生成搜索标签：类型名按驼峰拆分，描述和注释按中英文分词，去掉停用词
 func (p *Parser) generateTags(group *EnumGroup) []string {
 	tags := make(map[string]bool)
-
-	// 从名称中提取关键词
-	words := splitCamelCase(group.Name)
-	for _, word := range words {
-		tags[strings.ToLower(word)] = true
-	}
-
-	// 从描述中提取关键词
-	words = extractKeywords(group.Description)
-	for _, word := range words {
-		tags[strings.ToLower(word)] = true
+	add := func(text string) {
+		for _, word := range p.segmenter.Keywords(text) {
+			tags[word] = true
+		}
 	}
 
-	// 从枚举项中提取关键词
+	add(enumTypeName(group))
+	add(group.Description)
 	for _, item := range group.Items {
-		words = splitCamelCase(item.Name)
-		for _, word := range words {
-			tags[strings.ToLower(word)] = true
-		}
-		words = extractKeywords(item.Comment)
-		for _, word := range words {
-			tags[strings.ToLower(word)] = true
-		}
+		add(item.Name)
+		add(item.Comment)
 	}
 
 	// 转换为切片
@@ -217,65 +309,14 @@ func (p *Parser) generateTags(group *EnumGroup) []string {
 	return result
 }
 
-// 推断枚举分类
+// 推断枚举分类：按配置的规则依次匹配名称、描述和枚举项名
 func (p *Parser) inferCategory(group *EnumGroup) string {
-	name := strings.ToLower(group.Name)
-	switch {
-	case strings.Contains(name, "status") || strings.Contains(name, "state"):
-		return "状态"
-	case strings.Contains(name, "type"):
-		return "类型"
-	case strings.Contains(name, "flag"):
-		return "标志"
-	case strings.Contains(name, "mode"):
-		return "模式"
-	case strings.Contains(name, "level"):
-		return "级别"
-	default:
-		return "其他"
-	}
-}
-
-// 辅助函数：驼峰命名分词
-func splitCamelCase(s string) []string {
-	var words []string
-	var current string
-
-	for _, r := range s {
-		if unicode.IsUpper(r) {
-			if current != "" {
-				words = append(words, current)
-			}
-			current = string(r)
-		} else {
-			current += string(r)
+	for _, rule := range p.categoryRules {
+		if rule.matches(group) {
+			return rule.category
 		}
 	}
-	if current != "" {
-		words = append(words, current)
-	}
-	return words
-}
-
-// 辅助函数：从文本中提取关键词
-func extractKeywords(text string) []string {
-	// 移除常见的无意义词
-	stopWords := map[string]bool{
-		"the": true, "a": true, "an": true, "and": true, "or": true,
-		"in": true, "on": true, "at": true, "to": true, "for": true,
-	}
-
-	words := strings.Fields(text)
-	var keywords []string
-
-	for _, word := range words {
-		word = strings.ToLower(strings.Trim(word, ",.()[]{}\"'"))
-		if !stopWords[word] && len(word) > 2 {
-			keywords = append(keywords, word)
-		}
-	}
-
-	return keywords
+	return p.config.Category.Default
 }
 
 // 获取枚举组名称
@@ -307,21 +348,61 @@ func (p *Parser) getEnumGroupName(gen *ast.GenDecl) string {
 }
 
 // 解析枚举项
-func (p *Parser) parseEnumItems(gen *ast.GenDecl, groupComment string) []EnumItem {
+func (p *Parser) parseEnumItems(gen *ast.GenDecl, groupComment, rootPath, filename string, types map[string]constant.Kind) []EnumItem {
 	var items []EnumItem
 
+	// 常量声明中省略的值和类型沿用上一行，iota 按行递增
+	var lastValues []ast.Expr
+	var lastType ast.Expr
+	known := make(map[string]constant.Value)
+
+	// 本组声明的类型可以用于类型转换，底层类型未知时保持原值
 	for _, spec := range gen.Specs {
 		if vspec, ok := spec.(*ast.ValueSpec); ok {
+			if ident, ok := vspec.Type.(*ast.Ident); ok {
+				if _, exists := types[ident.Name]; !exists {
+					types[ident.Name] = constant.Unknown
+				}
+			}
+		}
+	}
+
+	for iota, spec := range gen.Specs {
+		if vspec, ok := spec.(*ast.ValueSpec); ok {
+			values, typ := vspec.Values, vspec.Type
+			if gen.Tok == token.CONST {
+				if len(values) == 0 {
+					values, typ = lastValues, lastType
+				} else {
+					lastValues, lastType = values, typ
+				}
+			}
+
 			for i, name := range vspec.Names {
 				item := EnumItem{
 					Name: name.Name,
+					SourceLocation: p.location(rootPath, filename,
+						p.fset.Position(name.Pos()).Line, p.fset.Position(vspec.End()).Line),
 				}
 
-				// 获取值
+				// 常量求值，省略的值按上一行的表达式和当前 iota 计算
+				if i < len(values) {
+					if v := evalConst(values[i], iota, known, types); v != nil {
+						known[name.Name] = v
+						item.ConstValue = constString(v)
+					}
+				}
+				// 文档中的取值：字面量保留源码写法（如 0x10），表达式显示求值结果，无法求值时保留原始写法
+				var written ast.Expr
 				if i < len(vspec.Values) {
-					switch v := vspec.Values[i].(type) {
-					case *ast.BasicLit:
-						item.Value = v.Value
+					written = vspec.Values[i]
+				}
+				if lit, ok := written.(*ast.BasicLit); ok {
+					item.Value = lit.Value
+				} else if item.ConstValue != "" {
+					item.Value = item.ConstValue
+				} else {
+					switch v := written.(type) {
 					case *ast.Ident:
 						item.Value = v.Name
 					case *ast.SelectorExpr:
@@ -330,6 +411,9 @@ func (p *Parser) parseEnumItems(gen *ast.GenDecl, groupComment string) []EnumIte
 						}
 					}
 				}
+				if ident, ok := typ.(*ast.Ident); ok {
+					item.GoType = ident.Name
+				}
 
 				// 获取注释
 				if vspec.Comment != nil {
@@ -348,7 +432,172 @@ func (p *Parser) parseEnumItems(gen *ast.GenDecl, groupComment string) []EnumIte
 	return items
 }
 
-func (p *Parser) parseSQLFile(filename string) error {
+// 常量类型转换支持的内置类型
+var builtinConstKinds = map[string]constant.Kind{
+	"bool": constant.Bool, "string": constant.String,
+	"int": constant.Int, "int8": constant.Int, "int16": constant.Int, "int32": constant.Int, "int64": constant.Int,
+	"uint": constant.Int, "uint8": constant.Int, "uint16": constant.Int, "uint32": constant.Int, "uint64": constant.Int,
+	"uintptr": constant.Int, "byte": constant.Int, "rune": constant.Int,
+	"float32": constant.Float, "float64": constant.Float,
+	"complex64": constant.Complex, "complex128": constant.Complex,
+}
+
+// 常量求值可以识别的类型转换：内置类型、文件中声明的类型和已解析枚举的类型。
+// 值为转换后的常量种类，底层类型未知的具名类型为 constant.Unknown
+func (p *Parser) constTypes(node *ast.File) map[string]constant.Kind {
+	types := make(map[string]constant.Kind, len(builtinConstKinds))
+	for name, kind := range builtinConstKinds {
+		types[name] = kind
+	}
+	for _, group := range p.enums {
+		for _, item := range group.Items {
+			if _, ok := types[item.GoType]; !ok && item.GoType != "" {
+				types[item.GoType] = constant.Unknown
+			}
+		}
+	}
+
+	// 文件中声明的类型，底层类型为内置类型或同文件的其他类型，如 type OrderStatus string
+	underlying := make(map[string]string)
+	for _, decl := range node.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				if ident, ok := ts.Type.(*ast.Ident); ok {
+					underlying[ts.Name.Name] = ident.Name
+				}
+			}
+		}
+	}
+	for name := range underlying {
+		base := name
+		for i := 0; i < len(underlying); i++ {
+			next, ok := underlying[base]
+			if !ok {
+				break
+			}
+			base = next
+		}
+		if kind, ok := builtinConstKinds[base]; ok {
+			types[name] = kind
+		}
+	}
+	return types
+}
+
+// 对常量表达式求值，支持字面量、iota、同组常量引用、类型转换和常见运算。
+// 只有 Fun 为已知类型的单参数调用才按类型转换处理，len 等函数调用无法求值
+func evalConst(expr ast.Expr, iota int, known map[string]constant.Value, types map[string]constant.Kind) constant.Value {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		v := constant.MakeFromLiteral(e.Value, e.Kind, 0)
+		if v.Kind() == constant.Unknown {
+			return nil
+		}
+		return v
+	case *ast.Ident:
+		if e.Name == "iota" {
+			return constant.MakeInt64(int64(iota))
+		}
+		return known[e.Name]
+	case *ast.ParenExpr:
+		return evalConst(e.X, iota, known, types)
+	case *ast.CallExpr:
+		// 类型转换，如 OrderStatus(1)、float64(1)
+		fun, ok := e.Fun.(*ast.Ident)
+		if !ok || len(e.Args) != 1 {
+			return nil
+		}
+		kind, ok := types[fun.Name]
+		if !ok {
+			return nil
+		}
+		if x := evalConst(e.Args[0], iota, known, types); x != nil {
+			return convertConst(x, kind)
+		}
+	case *ast.UnaryExpr:
+		if x := evalConst(e.X, iota, known, types); x != nil {
+			return constant.UnaryOp(e.Op, x, 0)
+		}
+	case *ast.BinaryExpr:
+		x, y := evalConst(e.X, iota, known, types), evalConst(e.Y, iota, known, types)
+		if x == nil || y == nil {
+			return nil
+		}
+		switch e.Op {
+		case token.SHL, token.SHR:
+			s, ok := constant.Uint64Val(y)
+			if !ok {
+				return nil
+			}
+			return constant.Shift(x, e.Op, uint(s))
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, e.Op, y))
+		case token.QUO:
+			if constant.Sign(y) == 0 {
+				return nil
+			}
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y)
+			}
+		}
+		return constant.BinaryOp(x, e.Op, y)
+	}
+	return nil
+}
+
+// 按目标类型的常量种类转换，不能转换时返回 nil
+func convertConst(v constant.Value, kind constant.Kind) constant.Value {
+	switch kind {
+	case constant.Unknown:
+		// 底层类型未知的具名类型，保持原值
+		return v
+	case constant.Int:
+		if v = constant.ToInt(v); v.Kind() == constant.Int {
+			return v
+		}
+	case constant.Float:
+		if v = constant.ToFloat(v); v.Kind() == constant.Float {
+			return v
+		}
+	case constant.Complex:
+		if v = constant.ToComplex(v); v.Kind() == constant.Complex {
+			return v
+		}
+	case constant.String:
+		switch v.Kind() {
+		case constant.String:
+			return v
+		case constant.Int:
+			// string(rune(65)) 得到 "A"
+			if n, ok := constant.Int64Val(v); ok {
+				return constant.MakeString(string(rune(n)))
+			}
+		}
+	case constant.Bool:
+		if v.Kind() == constant.Bool {
+			return v
+		}
+	}
+	return nil
+}
+
+// 常量值转换为 Go 字面量写法，字符串带引号，浮点数使用十进制小数
+func constString(v constant.Value) string {
+	switch v.Kind() {
+	case constant.String:
+		return strconv.Quote(constant.StringVal(v))
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return v.ExactString()
+}
+
+func (p *Parser) parseSQLFile(rootPath, filename string) error {
 	content, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return err
@@ -362,15 +611,16 @@ func (p *Parser) parseSQLFile(filename string) error {
 	// 分割成单独的语句
 	statements := splitSQLStatements(sqlContent)
 
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
+	for _, sqlStmt := range statements {
+		stmt := strings.TrimSpace(sqlStmt.Text)
 		if stmt == "" {
 			continue
 		}
 
 		// 尝试提取建表语句
 		if strings.HasPrefix(strings.ToUpper(stmt), "CREATE TABLE") {
-			if err := p.parseCreateTableByString(stmt); err != nil {
+			loc := p.location(rootPath, filename, sqlStmt.StartLine, sqlStmt.EndLine)
+			if err := p.parseCreateTableByString(stmt, loc); err != nil {
 				fmt.Printf("警告: 解析建表语句出错 (文件: %s): %v\n语句内容: %s\n",
 					filename, err, stmt)
 			}
@@ -390,13 +640,21 @@ func (p *Parser) parseSQLFile(filename string) error {
 	return nil
 }
 
-func splitSQLStatements(sql string) []string {
-	var statements []string
+// sqlStatement 表示一条 SQL 语句及其所在行范围
+type sqlStatement struct {
+	Text      string
+	StartLine int
+	EndLine   int
+}
+
+func splitSQLStatements(sql string) []sqlStatement {
+	var statements []sqlStatement
 	var currentStmt strings.Builder
+	var startLine int
 
 	// 按行处理，保留原始换行
 	lines := strings.Split(sql, "\n")
-	for _, line := range lines {
+	for i, line := range lines {
 		trimmedLine := strings.TrimSpace(line)
 
 		// 跳过空行
@@ -427,6 +685,8 @@ func splitSQLStatements(sql string) []string {
 		// 添加到当前语句
 		if currentStmt.Len() > 0 {
 			currentStmt.WriteString(" ")
+		} else {
+			startLine = i + 1
 		}
 		currentStmt.WriteString(trimmedLine)
 
@@ -434,7 +694,7 @@ func splitSQLStatements(sql string) []string {
 		if strings.HasSuffix(trimmedLine, ";") {
 			stmt := currentStmt.String()
 			if isRelevantStatement(stmt) {
-				statements = append(statements, stmt)
+				statements = append(statements, sqlStatement{Text: stmt, StartLine: startLine, EndLine: i + 1})
 			}
 			currentStmt.Reset()
 		}
@@ -447,7 +707,7 @@ func splitSQLStatements(sql string) []string {
 			lastStmt += ";"
 		}
 		if isRelevantStatement(lastStmt) {
-			statements = append(statements, lastStmt)
+			statements = append(statements, sqlStatement{Text: lastStmt, StartLine: startLine, EndLine: len(lines)})
 		}
 	}
 
@@ -462,17 +722,20 @@ func isRelevantStatement(stmt string) bool {
 		strings.HasPrefix(upperStmt, "ALTER TABLE")
 }
 
-func (p *Parser) parseCreateTableByString(stmt string) error {
+func (p *Parser) parseCreateTableByString(stmt string, loc SourceLocation) error {
 	// 提取表名，支持 public. 前缀和双引号
-	tableNameRegex := regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:"?public"?\.)?"?([^"\s(]+)"?`)
+	tableNameRegex := regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:"?([^"\s(.]+)"?\.)?"?([^"\s(]+)"?`)
 	matches := tableNameRegex.FindStringSubmatch(stmt)
-	if len(matches) < 2 {
+	if len(matches) < 3 {
 		return fmt.Errorf("无法提取表名")
 	}
 
-	// 处理表名
-	tableName := strings.Trim(matches[1], `"`)
-	tableName = strings.TrimPrefix(tableName, "public.")
+	// 处理表名，未指定 schema 时为 public
+	tableName := strings.Trim(matches[2], `"`)
+	schema := matches[1]
+	if schema == "" {
+		schema = "public"
+	}
 
 	// 提取字段定义
 	fieldsRegex := regexp.MustCompile(`\((.*)\)`)
@@ -499,9 +762,13 @@ func (p *Parser) parseCreateTableByString(stmt string) error {
 	}
 
 	// 存储表信息，使用不带 public. 前缀的表名
+	// 先出现的表注释需要保留
 	p.dbComments[tableName] = TableComment{
-		TableName: tableName,
-		Fields:    fields,
+		TableName:      tableName,
+		Schema:         schema,
+		Comment:        p.dbComments[tableName].Comment,
+		Fields:         fields,
+		SourceLocation: loc,
 	}
 
 	return nil
@@ -560,12 +827,21 @@ func parseFieldDef(fieldDef string) *FieldComment {
 	restDef := fieldDef[typeStart:]
 	fieldType := extractType(restDef)
 
+	var defaultValue string
+	if m := fieldDefaultRegex.FindStringSubmatch(restDef); m != nil {
+		defaultValue = strings.TrimSpace(m[1])
+	}
+
 	return &FieldComment{
 		FieldName: fieldName,
 		FieldType: fieldType,
+		Default:   defaultValue,
 	}
 }
 
+// 匹配字段定义中的 DEFAULT 表达式，到下一个列约束或定义末尾为止
+var fieldDefaultRegex = regexp.MustCompile(`(?is)\bDEFAULT\s+(.+?)(?:\s+(?:NOT\s+NULL|NULL|CONSTRAINT|CHECK|UNIQUE|PRIMARY\s+KEY|REFERENCES|GENERATED|COLLATE)\b|$)`)
+
 // 辅助函数：提取完整的类型定义
 func extractType(s string) string {
 	var result strings.Builder
@@ -596,24 +872,25 @@ func extractType(s string) string {
 func (p *Parser) parseCommentByString(stmt string) error {
 	// 提取注释内容，支持大小写不敏感的 COMMENT 和 IS 关键字，以及 TABLE 关键字
 	// 修改正则表达式以更好地处理 public. 前缀
-	commentRegex := regexp.MustCompile(`(?i)COMMENT\s+ON\s+(?:TABLE\s+|COLUMN\s+)?(?:public\.)?([^\s]+)\s+IS\s+'([^']*)'`)
+	commentRegex := regexp.MustCompile(`(?i)COMMENT\s+ON\s+(TABLE\s+|COLUMN\s+)?(?:public\.)?([^\s]+)\s+IS\s+'([^']*)'`)
 	matches := commentRegex.FindStringSubmatch(stmt)
-	if len(matches) < 3 {
+	if len(matches) < 4 {
 		// 如果没有匹配到，尝试使用双引号的版本
-		commentRegex = regexp.MustCompile(`(?i)COMMENT\s+ON\s+(?:TABLE\s+|COLUMN\s+)?(?:public\.)?([^\s]+)\s+IS\s+"([^"]*)"`)
+		commentRegex = regexp.MustCompile(`(?i)COMMENT\s+ON\s+(TABLE\s+|COLUMN\s+)?(?:public\.)?([^\s]+)\s+IS\s+"([^"]*)"`)
 		matches = commentRegex.FindStringSubmatch(stmt)
-		if len(matches) < 3 {
+		if len(matches) < 4 {
 			return fmt.Errorf("无法提取注释内容")
 		}
 	}
 
-	target := strings.Trim(matches[1], `"`)
-	comment := decodeComment(matches[2])
+	target := strings.Trim(matches[2], `"`)
+	comment := decodeComment(matches[3])
 
-	// 处理表注释
-	if !strings.Contains(target, ".") {
-		// 移除可能的 public. 前缀
-		target = strings.TrimPrefix(target, "public.")
+	// 处理表注释，"schema"."table" 形式只取表名
+	isTable := strings.EqualFold(strings.TrimSpace(matches[1]), "TABLE")
+	if isTable || !strings.Contains(target, ".") {
+		// 移除可能的 schema 前缀
+		target = strings.Trim(target[strings.LastIndex(target, ".")+1:], `"`)
 		if table, ok := p.dbComments[target]; ok {
 			table.Comment = comment
 			p.dbComments[target] = table
@@ -697,6 +974,15 @@ func (p *Parser) mergeEnumGroup(existing, new *EnumGroup) {
 		}
 	}
 
+	existing.UsagesScanned = existing.UsagesScanned && new.UsagesScanned
+
+	// 合并映射的数据库列
+	for _, column := range new.Columns {
+		if !containsString(existing.Columns, column) {
+			existing.Columns = append(existing.Columns, column)
+		}
+	}
+
 	// 合并标签
 	existingTags := make(map[string]bool)
 	for _, tag := range existing.Tags {
@@ -719,9 +1005,30 @@ func (p *Parser) ToMarkdown() string {
 
 	// 生成枚举文档
 	if len(p.enums) > 0 {
+		writeRAGMeta(&md, map[string]string{"doc_type": "enum"})
 		md.WriteString("# 枚举类型\n\n")
-		for _, enum := range p.enums {
-			md.WriteString(fmt.Sprintf("## %s\n\n", enum.Name))
+		var counts []string
+		for _, c := range p.categoryCounts() {
+			counts = append(counts, fmt.Sprintf("%s %d", c.Category, c.Count))
+		}
+		md.WriteString(fmt.Sprintf("**分类统计：** %s\n\n", strings.Join(counts, " · ")))
+		// 对枚举名排序，保证输出顺序一致
+		var enumNames []string
+		for name := range p.enums {
+			enumNames = append(enumNames, name)
+		}
+		sort.Strings(enumNames)
+
+		for _, name := range enumNames {
+			enum := p.enums[name]
+			writeRAGMeta(&md, map[string]string{
+				"doc_type": "enum",
+				"project":  enum.Project,
+				"package":  enum.Package,
+				"category": enum.Category,
+				"name":     enumTypeName(enum),
+			})
+			md.WriteString(fmt.Sprintf("## %s\n\n", qualify(enum.Project, enum.Name)))
 			// 使用更友好的标签格式
 			if len(enum.Tags) > 0 {
 				md.WriteString("**标签：** ")
@@ -733,21 +1040,49 @@ func (p *Parser) ToMarkdown() string {
 				}
 				md.WriteString("\n\n")
 			}
-			md.WriteString("| 变量 | 原值 | 描述 |\n|---|---|---|\n")
+			if enum.Category != "" {
+				md.WriteString(fmt.Sprintf("**分类：** %s\n\n", enum.Category))
+			}
+			if enum.File != "" {
+				md.WriteString(fmt.Sprintf("**来源：** %s\n\n", enum.SourceLocation.Markdown()))
+			}
+			if len(enum.Columns) > 0 {
+				md.WriteString(fmt.Sprintf("**数据库列：** `%s`\n\n", strings.Join(enum.Columns, "`、`")))
+			}
+			header := []string{"变量", "原值", "描述", "位置"}
+			if p.usagesScanned {
+				header = append(header, "引用次数")
+			}
+			md.WriteString("| " + strings.Join(header, " | ") + " |\n")
+			md.WriteString(strings.Repeat("|---", len(header)) + "|\n")
 			for _, value := range enum.Items {
 				valueStr := ""
 				if value.Value != nil {
 					valueStr = fmt.Sprintf("%v", value.Value)
 				}
-				md.WriteString(fmt.Sprintf("| %s | %s | %s |\n",
-					value.Name, valueStr, value.Comment))
+				row := []string{value.Name, valueStr, value.Comment, itemLocation(enum.File, value.SourceLocation)}
+				if p.usagesScanned {
+					count := "-"
+					if enum.UsagesScanned {
+						count = fmt.Sprintf("%d", value.UsageCount)
+						if value.Unused {
+							count = "0（未使用）"
+						}
+					}
+					row = append(row, count)
+				}
+				md.WriteString("| " + strings.Join(row, " | ") + " |\n")
 			}
 			md.WriteString("\n")
+			if enum.UsagesScanned {
+				writeUsageExamples(&md, enum)
+			}
 		}
 	}
 
 	// 生成数据库表文档
 	if len(p.dbComments) > 0 {
+		writeRAGMeta(&md, map[string]string{"doc_type": "table"})
 		md.WriteString("# 数据库表\n\n")
 		// 先对表名进行排序，保证输出顺序一致
 		var tableNames []string
@@ -758,6 +1093,12 @@ func (p *Parser) ToMarkdown() string {
 
 		for _, tableName := range tableNames {
 			table := p.dbComments[tableName]
+			writeRAGMeta(&md, map[string]string{
+				"doc_type": "table",
+				"project":  table.Project,
+				"schema":   table.Schema,
+				"name":     tableName,
+			})
 			// 如果有表注释，将其添加到表名后面
 			if table.Comment != "" {
 				md.WriteString(fmt.Sprintf("## %s（%s）\n\n", tableName, table.Comment))
@@ -765,6 +1106,10 @@ func (p *Parser) ToMarkdown() string {
 				md.WriteString(fmt.Sprintf("## %s\n\n", tableName))
 			}
 
+			if table.File != "" {
+				md.WriteString(fmt.Sprintf("**来源：** %s\n\n", table.SourceLocation.Markdown()))
+			}
+
 			md.WriteString("| 字段 | 类型 | 描述 |\n|---|---|---|\n")
 			for _, field := range table.Fields {
 				comment := field.Comment
@@ -778,9 +1123,31 @@ func (p *Parser) ToMarkdown() string {
 		}
 	}
 
+	// 生成接口数据模型、错误码、路由和配置项文档
+	p.writeAPISchemas(&md)
+	p.writeErrorCodes(&md)
+	p.writeRoutes(&md)
+	p.writeConfigStructs(&md)
+
 	return md.String()
 }
 
+// 写入一行元数据注释，渲染后不可见。rag 加载文档时按注释切分章节，
+// 并把其中的项目、文档类型、包等附加到分块元数据上，用于过滤检索。空值不写入
+func writeRAGMeta(md *strings.Builder, meta map[string]string) {
+	values := make(map[string]string, len(meta))
+	for key, value := range meta {
+		if value != "" {
+			values[key] = value
+		}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return
+	}
+	md.WriteString(fmt.Sprintf("<!-- docgen: %s -->\n", data))
+}
+
 func removeDollarQuotes(sql string) string {
 	// 匹配 $$ 或 $tag$ 之间的内容
 	dollarRegex := regexp.MustCompile(`\$[^$]*\$.*?\$[^$]*\$`)
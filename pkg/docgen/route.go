@@ -0,0 +1,797 @@
+package docgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 路由传递的最大调用深度，防止递归注册导致死循环
+const maxRouteCallDepth = 8
+
+// Route 表示一条 HTTP 路由
+type Route struct {
+	Method  string `json:"method"`  // 请求方法，ANY 表示不限
+	Path    string `json:"path"`    // 完整路径（含分组前缀）
+	Handler string `json:"handler"` // 处理函数
+	Doc     string `json:"doc"`     // 处理函数的文档注释
+	Package string `json:"package"` // 注册路由所在的包
+	SourceLocation
+}
+
+// gin/echo/chi 风格的路由注册方法
+var routeMethods = map[string]string{
+	"GET": "GET", "POST": "POST", "PUT": "PUT", "DELETE": "DELETE", "PATCH": "PATCH",
+	"HEAD": "HEAD", "OPTIONS": "OPTIONS", "CONNECT": "CONNECT", "TRACE": "TRACE", "Any": "ANY",
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Delete": "DELETE", "Patch": "PATCH",
+	"Head": "HEAD", "Options": "OPTIONS", "Connect": "CONNECT", "Trace": "TRACE",
+}
+
+// 已知路由库的导入路径（去掉 /vN 版本后缀）-> 库名
+var routerLibs = map[string]string{
+	"net/http":                 "http",
+	"github.com/gin-gonic/gin": "gin",
+	"github.com/labstack/echo": "echo",
+	"github.com/go-chi/chi":    "chi",
+	"github.com/gorilla/mux":   "mux",
+}
+
+// 各路由库中可以注册路由的类型
+var routerTypeNames = map[string]map[string]bool{
+	"http": {"ServeMux": true},
+	"gin":  {"Engine": true, "RouterGroup": true, "IRouter": true, "IRoutes": true},
+	"echo": {"Echo": true, "Group": true},
+	"chi":  {"Router": true, "Mux": true},
+	"mux":  {"Router": true},
+}
+
+// 各路由库创建路由器的函数
+var routerConstructors = map[string]map[string]bool{
+	"http": {"NewServeMux": true},
+	"gin":  {"Default": true, "New": true},
+	"echo": {"New": true},
+	"chi":  {"NewRouter": true, "NewMux": true},
+	"mux":  {"NewRouter": true},
+}
+
+// routerRef 描述一个路由器变量：来自函数参数（param >= 0）或本函数创建（param = -1），以及累积的路径前缀
+type routerRef struct {
+	param  int
+	prefix string
+}
+
+// 路由器作为参数传入其他函数
+type routerCall struct {
+	callees []string
+	arg     int
+	ref     routerRef
+}
+
+// 依赖参数前缀的路由
+type pendingRoute struct {
+	param int
+	route Route
+}
+
+type funcRoutes struct {
+	pending []pendingRoute
+	calls   []routerCall
+}
+
+// routeEnv 记录函数内的路由器变量，以及类型已知的变量（用于按接收者类型解析方法）
+type routeEnv struct {
+	routers map[string]routerRef
+	types   map[string]string // 变量名 -> pkg.Type
+}
+
+func newRouteEnv() routeEnv {
+	return routeEnv{routers: make(map[string]routerRef), types: make(map[string]string)}
+}
+
+func (e routeEnv) copy() routeEnv {
+	child := newRouteEnv()
+	for k, v := range e.routers {
+		child.routers[k] = v
+	}
+	for k, v := range e.types {
+		child.types[k] = v
+	}
+	return child
+}
+
+// 记录变量的新值：路由器、已知类型的值，或都不是（清除旧记录）
+func (s *routeScan) assign(file *goSourceFile, env routeEnv, name string, value ast.Expr) {
+	if ref, ok := s.routerExpr(file, value, env); ok {
+		env.routers[name] = ref
+	} else {
+		delete(env.routers, name)
+	}
+	if t := s.exprType(file, value, env); t != "" {
+		env.types[name] = t
+	} else {
+		delete(env.types, name)
+	}
+}
+
+type goSourceFile struct {
+	node     *ast.File
+	rootPath string
+	filename string
+	imports  map[string]string // 引用名 -> 导入路径
+}
+
+// 返回引用名对应的路由库，不是已知路由库时返回空
+func (f *goSourceFile) routerLib(name string) string {
+	importPath, ok := f.imports[name]
+	if !ok {
+		return ""
+	}
+	if base := path.Base(importPath); strings.HasPrefix(base, "v") && len(base) > 1 && strings.Trim(base[1:], "0123456789") == "" {
+		importPath = path.Dir(importPath)
+	}
+	return routerLibs[importPath]
+}
+
+// 判断类型表达式是否为已知的路由器类型，如 *gin.Engine、chi.Router、*http.ServeMux
+func (f *goSourceFile) isRouterType(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && routerTypeNames[f.routerLib(x.Name)][sel.Sel.Name]
+}
+
+// 返回类型表达式的 pkg.Type 形式，忽略指针和泛型参数；无法识别时返回空
+func (f *goSourceFile) typeKey(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return f.typeKey(t.X)
+	case *ast.IndexExpr:
+		return f.typeKey(t.X)
+	case *ast.Ident:
+		return f.node.Name.Name + "." + t.Name
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			if _, ok := f.imports[x.Name]; ok {
+				return x.Name + "." + t.Sel.Name
+			}
+		}
+	}
+	return ""
+}
+
+type routeScan struct {
+	files        []*goSourceFile
+	docs         map[string]string    // pkg.Func 或 pkg.Recv.Method -> 文档注释
+	decls        map[string]bool      // 模块内声明的函数和方法
+	results      map[string]string    // 函数 key -> 第一个返回值的类型
+	fieldTypes   map[string]string    // pkg.Type.field -> 字段类型
+	routerFields map[string]bool      // 类型为路由器的结构体字段 pkg.Type.field
+	globals      map[string]routerRef // 包级路由器变量 pkg.name
+	funcs        map[string]*funcRoutes
+	called       map[string]bool
+	routes       []Route
+}
+
+// ParseRoutes 静态识别 net/http、gin、echo、chi 风格的路由注册，
+// 包括分组前缀和把路由器作为参数传入注册函数的写法
+func (p *Parser) ParseRoutes(rootPath string) ([]Route, error) {
+	scan := &routeScan{
+		docs:         make(map[string]string),
+		decls:        make(map[string]bool),
+		results:      make(map[string]string),
+		fieldTypes:   make(map[string]string),
+		routerFields: make(map[string]bool),
+		globals:      make(map[string]routerRef),
+		funcs:        make(map[string]*funcRoutes),
+		called:       make(map[string]bool),
+	}
+
+	// 先收集所有函数文档和类型信息，处理函数、路由器字段可能定义在其他文件
+	err := p.walkFiles(rootPath, ".go", func(filename string) error {
+		node, err := p.parseGoFile(filename)
+		if err != nil {
+			return err
+		}
+		file := &goSourceFile{node: node, rootPath: rootPath, filename: filename, imports: importNames(node)}
+		scan.files = append(scan.files, file)
+
+		for _, decl := range node.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				key := node.Name.Name + "." + funcDeclName(fn)
+				scan.decls[key] = true
+				if fn.Doc != nil {
+					doc := strings.Join(strings.Fields(fn.Doc.Text()), " ")
+					scan.docs[key] = strings.TrimPrefix(doc, fn.Name.Name+" ")
+				}
+				if fn.Type.Results != nil && len(fn.Type.Results.List) > 0 {
+					if t := file.typeKey(fn.Type.Results.List[0].Type); t != "" {
+						scan.results[key] = t
+					}
+				}
+			}
+		}
+		scan.collectTypes(file)
+		return nil
+	})
+	if err != nil {
+		return p.routes, err
+	}
+	for _, file := range scan.files {
+		scan.collectGlobals(file)
+	}
+
+	for _, file := range scan.files {
+		for _, decl := range file.node.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			key := file.node.Name.Name + "." + funcDeclName(fn)
+			fr := &funcRoutes{}
+			scan.funcs[key] = fr
+
+			env := newRouteEnv()
+			if fn.Recv != nil && len(fn.Recv.List) > 0 && len(fn.Recv.List[0].Names) > 0 {
+				env.types[fn.Recv.List[0].Names[0].Name] = file.typeKey(fn.Recv.List[0].Type)
+			}
+			// 只有路由器类型的参数才可能注册路由，其他参数记录类型用于解析方法
+			i := 0
+			for _, field := range fn.Type.Params.List {
+				names := field.Names
+				if len(names) == 0 {
+					names = []*ast.Ident{{Name: "_"}}
+				}
+				for _, name := range names {
+					if file.isRouterType(field.Type) {
+						env.routers[name.Name] = routerRef{param: i}
+					} else if t := file.typeKey(field.Type); t != "" {
+						env.types[name.Name] = t
+					}
+					i++
+				}
+			}
+			p.analyzeRoutes(scan, file, fr, fn.Body, env)
+		}
+	}
+
+	// 从未被调用的注册函数出发，把参数路由器上的路由展开为完整路径
+	keys := make([]string, 0, len(scan.funcs))
+	for key := range scan.funcs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fr := scan.funcs[key]
+		for _, call := range fr.calls {
+			if call.ref.param < 0 {
+				for _, callee := range call.callees {
+					scan.expand(callee, call.arg, call.ref.prefix, 0)
+				}
+			}
+		}
+		if !scan.called[key] {
+			params := make(map[int]bool)
+			for _, pending := range fr.pending {
+				params[pending.param] = true
+			}
+			for _, call := range fr.calls {
+				if call.ref.param >= 0 {
+					params[call.ref.param] = true
+				}
+			}
+			for param := range params {
+				scan.expand(key, param, "", 0)
+			}
+		}
+	}
+
+	p.routes = append(p.routes, scan.routes...)
+	p.sortRoutes()
+	return p.routes, nil
+}
+
+// 收集结构体字段的类型，路由器字段单独记录
+func (s *routeScan) collectTypes(file *goSourceFile) {
+	for _, decl := range file.node.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				for _, name := range field.Names {
+					key := file.node.Name.Name + "." + ts.Name.Name + "." + name.Name
+					if file.isRouterType(field.Type) {
+						s.routerFields[key] = true
+					} else if t := file.typeKey(field.Type); t != "" {
+						s.fieldTypes[key] = t
+					}
+				}
+			}
+		}
+	}
+}
+
+// 收集包级路由器变量，如 var router = gin.Default()
+func (s *routeScan) collectGlobals(file *goSourceFile) {
+	for _, decl := range file.node.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vspec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for i, name := range vspec.Names {
+				isRouter := vspec.Type != nil && file.isRouterType(vspec.Type)
+				if !isRouter && i < len(vspec.Values) {
+					_, isRouter = s.routerExpr(file, vspec.Values[i], newRouteEnv())
+				}
+				if isRouter {
+					s.globals[file.node.Name.Name+"."+name.Name] = routerRef{param: -1}
+				}
+			}
+		}
+	}
+}
+
+// 路由按路径、方法排序并去重
+func (p *Parser) sortRoutes() {
+	sort.SliceStable(p.routes, func(i, j int) bool {
+		a, b := p.routes[i], p.routes[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	p.routes = dedupeRoutes(p.routes)
+}
+
+// 按源码顺序分析函数体中的路由器赋值、路由注册和注册函数调用
+func (p *Parser) analyzeRoutes(scan *routeScan, file *goSourceFile, fr *funcRoutes, body ast.Node, env routeEnv) {
+	pkgName := file.node.Name.Name
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				switch {
+				case len(n.Lhs) == len(n.Rhs):
+					scan.assign(file, env, ident.Name, n.Rhs[i])
+				case i == 0 && len(n.Rhs) == 1:
+					// h, err := NewHandler() 取第一个返回值
+					scan.assign(file, env, ident.Name, n.Rhs[0])
+				}
+			}
+
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				switch {
+				case n.Type != nil && file.isRouterType(n.Type):
+					env.routers[name.Name] = routerRef{param: -1}
+				case i < len(n.Values):
+					scan.assign(file, env, name.Name, n.Values[i])
+				case n.Type != nil:
+					env.types[name.Name] = file.typeKey(n.Type)
+				}
+			}
+
+		case *ast.FuncLit:
+			// 闭包单独分析，避免参数同名覆盖外层变量
+			p.analyzeRoutes(scan, file, fr, n.Body, env.copy())
+			return false
+
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				scan.recordCall(fr, file, n, env)
+				return true
+			}
+			recv, isRouter := scan.routerExpr(file, sel.X, env)
+			method := sel.Sel.Name
+
+			// chi 的 Route/Group 闭包
+			if (method == "Route" || method == "Group") && isRouter {
+				prefix, rest := "", n.Args
+				if method == "Route" && len(n.Args) == 2 {
+					s, ok := stringArg(n.Args, 0)
+					if !ok {
+						return true
+					}
+					prefix, rest = s, n.Args[1:]
+				}
+				if len(rest) == 1 {
+					if lit, ok := rest[0].(*ast.FuncLit); ok {
+						childEnv := env.copy()
+						if names := paramNames(lit.Type); len(names) > 0 {
+							childEnv.routers[names[0]] = routerRef{param: recv.param, prefix: joinRoutePath(recv.prefix, prefix)}
+						}
+						p.analyzeRoutes(scan, file, fr, lit.Body, childEnv)
+						return false
+					}
+				}
+			}
+
+			// 只识别已知路由器上的注册，以及 net/http 包级的 Handle/HandleFunc（注册到默认 ServeMux）
+			x, _ := sel.X.(*ast.Ident)
+			defaultMux := x != nil && file.routerLib(x.Name) == "http" && (method == "Handle" || method == "HandleFunc")
+			if isRouter || defaultMux {
+				if route, ok := p.routeCall(scan, file, n, method, env); ok {
+					if !isRouter {
+						recv = routerRef{param: -1}
+					}
+					route.Path = joinRoutePath(recv.prefix, route.Path)
+					route.Package = pkgName
+					if recv.param >= 0 {
+						fr.pending = append(fr.pending, pendingRoute{param: recv.param, route: route})
+					} else {
+						scan.routes = append(scan.routes, route)
+					}
+					return true
+				}
+			}
+			scan.recordCall(fr, file, n, env)
+		}
+		return true
+	})
+}
+
+// 识别单个路由注册调用
+func (p *Parser) routeCall(scan *routeScan, file *goSourceFile, call *ast.CallExpr, method string, env routeEnv) (Route, bool) {
+	var httpMethod, routePath string
+	args := call.Args
+
+	switch {
+	case routeMethods[method] != "" && len(args) >= 2:
+		s, ok := stringArg(args, 0)
+		if !ok || !isRoutePath(s) {
+			return Route{}, false
+		}
+		httpMethod, routePath = routeMethods[method], s
+
+	case method == "HandleFunc" || method == "Handle" || method == "Method" || method == "MethodFunc":
+		if len(args) >= 3 {
+			// gin 的 Handle("GET", "/path", h) 与 chi 的 Method("GET", "/path", h)
+			m, ok1 := stringArg(args, 0)
+			s, ok2 := stringArg(args, 1)
+			if ok1 && ok2 && isRoutePath(s) && routeMethods[strings.ToUpper(m)] != "" {
+				httpMethod, routePath = strings.ToUpper(m), s
+				break
+			}
+		}
+		if len(args) < 2 {
+			return Route{}, false
+		}
+		s, ok := stringArg(args, 0)
+		if !ok || !strings.Contains(s, "/") {
+			return Route{}, false
+		}
+		// Go 1.22 的 "GET /path" 模式
+		httpMethod, routePath = "ANY", s
+		if fields := strings.Fields(s); len(fields) == 2 {
+			httpMethod, routePath = fields[0], fields[1]
+		}
+
+	default:
+		return Route{}, false
+	}
+
+	handler, doc := scan.resolveHandler(file, args[len(args)-1], env)
+	line := p.fset.Position(call.Pos()).Line
+	return Route{
+		Method:         httpMethod,
+		Path:           routePath,
+		Handler:        handler,
+		Doc:            doc,
+		SourceLocation: p.location(file.rootPath, file.filename, line, p.fset.Position(call.End()).Line),
+	}, true
+}
+
+// 记录把路由器作为参数传给其他函数的调用
+func (s *routeScan) recordCall(fr *funcRoutes, file *goSourceFile, call *ast.CallExpr, env routeEnv) {
+	for i, arg := range call.Args {
+		ref, ok := s.routerExpr(file, arg, env)
+		if !ok {
+			continue
+		}
+		callees := s.resolveFunc(file, call.Fun, env)
+		if len(callees) == 0 {
+			continue
+		}
+		for _, callee := range callees {
+			s.called[callee] = true
+		}
+		fr.calls = append(fr.calls, routerCall{callees: callees, arg: i, ref: ref})
+	}
+}
+
+// 以 prefix 作为函数 key 的第 param 个参数的前缀，展开其中的路由
+func (s *routeScan) expand(key string, param int, prefix string, depth int) {
+	fr, ok := s.funcs[key]
+	if !ok || depth > maxRouteCallDepth {
+		return
+	}
+	for _, pending := range fr.pending {
+		if pending.param == param {
+			route := pending.route
+			route.Path = joinRoutePath(prefix, route.Path)
+			s.routes = append(s.routes, route)
+		}
+	}
+	for _, call := range fr.calls {
+		if call.ref.param == param {
+			for _, callee := range call.callees {
+				s.expand(callee, call.arg, joinRoutePath(prefix, call.ref.prefix), depth+1)
+			}
+		}
+	}
+}
+
+// 解析被调用的模块内函数，返回 pkg.Func 或 pkg.Recv.Method 形式的 key。
+// 方法按接收者的类型解析，类型未知时不解析
+func (s *routeScan) resolveFunc(file *goSourceFile, fun ast.Expr, env routeEnv) []string {
+	pkgName := file.node.Name.Name
+	switch f := fun.(type) {
+	case *ast.Ident:
+		if key := pkgName + "." + f.Name; s.decls[key] {
+			return []string{key}
+		}
+	case *ast.SelectorExpr:
+		if x, ok := f.X.(*ast.Ident); ok {
+			if _, isPkg := file.imports[x.Name]; isPkg && env.types[x.Name] == "" {
+				if key := x.Name + "." + f.Sel.Name; s.decls[key] {
+					return []string{key}
+				}
+				return nil
+			}
+		}
+		if t := s.exprType(file, f.X, env); t != "" {
+			if key := t + "." + f.Sel.Name; s.decls[key] {
+				return []string{key}
+			}
+		}
+	}
+	return nil
+}
+
+// 推断表达式的类型（pkg.Type 形式），支持变量、字段、复合字面量、new 和模块内函数的返回值
+func (s *routeScan) exprType(file *goSourceFile, expr ast.Expr, env routeEnv) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return env.types[e.Name]
+	case *ast.SelectorExpr:
+		if t := s.exprType(file, e.X, env); t != "" {
+			return s.fieldTypes[t+"."+e.Sel.Name]
+		}
+	case *ast.StarExpr:
+		return s.exprType(file, e.X, env)
+	case *ast.ParenExpr:
+		return s.exprType(file, e.X, env)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return s.exprType(file, e.X, env)
+		}
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return file.typeKey(e.Type)
+		}
+	case *ast.CallExpr:
+		if ident, ok := e.Fun.(*ast.Ident); ok && ident.Name == "new" && len(e.Args) == 1 {
+			return file.typeKey(e.Args[0])
+		}
+		if keys := s.resolveFunc(file, e.Fun, env); len(keys) == 1 {
+			return s.results[keys[0]]
+		}
+	}
+	return ""
+}
+
+// 解析处理函数名称及其文档注释
+func (s *routeScan) resolveHandler(file *goSourceFile, expr ast.Expr, env routeEnv) (string, string) {
+	switch h := expr.(type) {
+	case *ast.FuncLit:
+		return "匿名函数", ""
+	case *ast.CallExpr:
+		// http.HandlerFunc(fn) 之类的类型转换，或返回处理函数的工厂函数
+		if calleeName(h.Fun) == "http.HandlerFunc" && len(h.Args) == 1 {
+			return s.resolveHandler(file, h.Args[0], env)
+		}
+		name, doc := s.resolveHandler(file, h.Fun, env)
+		return name + "()", doc
+	case *ast.Ident, *ast.SelectorExpr:
+		keys := s.resolveFunc(file, h, env)
+		if len(keys) == 1 {
+			return keys[0], s.docs[keys[0]]
+		}
+		return exprString(h), ""
+	}
+	return exprString(expr), ""
+}
+
+// 判断表达式是否为已知路由器，返回其来源与前缀。路由器来自路由器类型的参数、变量和结构体字段，
+// 或者路由库的构造函数（gin.Default()、chi.NewRouter()、http.NewServeMux() 等）
+func (s *routeScan) routerExpr(file *goSourceFile, expr ast.Expr, env routeEnv) (routerRef, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if ref, ok := env.routers[e.Name]; ok {
+			return ref, true
+		}
+		if _, shadowed := env.types[e.Name]; shadowed {
+			return routerRef{}, false
+		}
+		ref, ok := s.globals[file.node.Name.Name+"."+e.Name]
+		return ref, ok
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && e.Sel.Name == "DefaultServeMux" && file.routerLib(x.Name) == "http" {
+			return routerRef{param: -1}, true
+		}
+		// 结构体中的路由器字段，如 s.router
+		if t := s.exprType(file, e.X, env); t != "" && s.routerFields[t+"."+e.Sel.Name] {
+			return routerRef{param: -1}, true
+		}
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return routerRef{}, false
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && routerConstructors[file.routerLib(x.Name)][sel.Sel.Name] {
+			return routerRef{param: -1}, true
+		}
+		switch sel.Sel.Name {
+		case "Group":
+			// gin/echo 的 Group("/prefix", middlewares...)
+			if prefix, ok := stringArg(e.Args, 0); ok {
+				if ref, ok := s.routerExpr(file, sel.X, env); ok {
+					return routerRef{param: ref.param, prefix: joinRoutePath(ref.prefix, prefix)}, true
+				}
+			}
+		case "With":
+			// chi 的 With(middlewares...) 不改变前缀
+			return s.routerExpr(file, sel.X, env)
+		}
+	case *ast.UnaryExpr:
+		return s.routerExpr(file, e.X, env)
+	case *ast.ParenExpr:
+		return s.routerExpr(file, e.X, env)
+	}
+	return routerRef{}, false
+}
+
+func joinRoutePath(prefix, p string) string {
+	if prefix == "" {
+		return p
+	}
+	if p == "" || p == "/" {
+		return prefix
+	}
+	joined := path.Join(prefix, p)
+	if strings.HasSuffix(p, "/") {
+		joined += "/"
+	}
+	return joined
+}
+
+func isRoutePath(s string) bool {
+	return s == "" || strings.HasPrefix(s, "/")
+}
+
+func paramNames(ft *ast.FuncType) []string {
+	var names []string
+	if ft.Params == nil {
+		return names
+	}
+	for _, field := range ft.Params.List {
+		if len(field.Names) == 0 {
+			names = append(names, "_")
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// 返回文件中导入包的引用名及其导入路径
+func importNames(node *ast.File) map[string]string {
+	names := make(map[string]string)
+	for _, imp := range node.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if imp.Name != nil {
+			if imp.Name.Name != "_" && imp.Name.Name != "." {
+				names[imp.Name.Name] = importPath
+			}
+			continue
+		}
+		name := path.Base(importPath)
+		// 去掉 gopkg.in/yaml.v3 或 /v2 之类的版本后缀
+		if i := strings.Index(name, ".v"); i > 0 {
+			name = name[:i]
+		}
+		if strings.HasPrefix(name, "v") && len(name) > 1 && strings.Trim(name[1:], "0123456789") == "" {
+			name = path.Base(path.Dir(importPath))
+		}
+		names[strings.TrimPrefix(name, "go-")] = importPath
+	}
+	return names
+}
+
+func dedupeRoutes(routes []Route) []Route {
+	seen := make(map[string]bool)
+	var result []Route
+	for _, route := range routes {
+		key := fmt.Sprintf("%s %s %s", route.Method, route.Path, route.SourceLocation.String())
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, route)
+		}
+	}
+	return result
+}
+
+// 返回表达式的简短源码形式
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(e.X)
+	case *ast.CallExpr:
+		return exprString(e.Fun) + "()"
+	case *ast.IndexExpr:
+		return exprString(e.X) + "[" + exprString(e.Index) + "]"
+	case *ast.ArrayType:
+		return "[]" + exprString(e.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(e.Key) + "]" + exprString(e.Value)
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.BasicLit:
+		return e.Value
+	}
+	return ""
+}
+
+// 生成路由文档
+func (p *Parser) writeRoutes(md *strings.Builder) {
+	if len(p.routes) == 0 {
+		return
+	}
+
+	writeRAGMeta(md, map[string]string{"doc_type": "route"})
+	md.WriteString("# 接口路由\n\n")
+	md.WriteString("| 方法 | 路径 | 处理函数 | 说明 | 来源 |\n|---|---|---|---|---|\n")
+	for _, route := range p.routes {
+		md.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+			route.Method, orDash(tableCell(route.Path)), tableCell(route.Handler), orDash(tableCell(route.Doc)),
+			route.SourceLocation.Markdown()))
+	}
+	md.WriteString("\n")
+}
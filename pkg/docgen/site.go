@@ -0,0 +1,325 @@
+package docgen
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 站点模板和静态资源，全部内嵌，生成的站点可离线浏览
+//
+//go:embed site
+var siteFS embed.FS
+
+var siteTemplates = map[string]*template.Template{
+	"index": parseSiteTemplate("index"),
+	"enum":  parseSiteTemplate("enum"),
+	"table": parseSiteTemplate("table"),
+}
+
+func parseSiteTemplate(name string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"value":     valueText,
+		"usageKind": func(kind string) string { return usageKindLabels[kind] },
+	}).ParseFS(siteFS, "site/layout.html", "site/"+name+".html"))
+}
+
+// siteLink 侧边栏和页面中的链接，URL 相对站点根目录
+type siteLink struct {
+	Title       string
+	URL         string
+	Description string
+}
+
+type siteNavGroup struct {
+	Name  string
+	Open  bool
+	Links []siteLink
+}
+
+type siteNavSection struct {
+	Title  string
+	Groups []siteNavGroup
+}
+
+type siteEnum struct {
+	TypeName string
+	Group    *EnumGroup
+	Columns  []siteLink
+}
+
+type siteField struct {
+	FieldComment
+	Enum *siteLink
+}
+
+type siteTable struct {
+	Kind        string // 数据表或接口数据模型
+	SchemaLabel string
+	Table       TableComment
+	Fields      []siteField
+}
+
+type siteIndex struct {
+	Categories  []categoryCount
+	EnumCount   int
+	TableCount  int
+	SchemaCount int
+}
+
+// sitePage 渲染一个页面所需的数据
+type sitePage struct {
+	Project string
+	Title   string
+	Root    string // 当前页面到站点根目录的相对路径
+	Current string
+	Nav     []siteNavSection
+	Index   siteIndex
+	Enum    *siteEnum
+	Table   *siteTable
+}
+
+// siteSearchEntry 搜索索引条目
+type siteSearchEntry struct {
+	Title   string   `json:"title"`
+	Kind    string   `json:"kind"`
+	URL     string   `json:"url"`
+	Tags    []string `json:"tags"`
+	Summary string   `json:"summary"`
+	Text    string   `json:"text"`
+}
+
+var slugUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// GenerateSite 生成静态 HTML 站点，每个枚举、数据表和接口数据模型一个页面，
+// 侧边栏按包和 schema 分组，搜索索引写成 JS 文件以便通过 file:// 离线打开。
+// 返回相对站点根目录的文件路径到内容的映射
+func (p *Parser) GenerateSite(project string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	used := make(map[string]bool)
+	slug := func(dir string, parts ...string) string {
+		base := slugUnsafe.ReplaceAllString(strings.Join(parts, "."), "_")
+		name := fmt.Sprintf("%s/%s.html", dir, base)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s/%s-%d.html", dir, base, i)
+		}
+		used[name] = true
+		return name
+	}
+
+	// 分配页面地址
+	enumURLs := make(map[*EnumGroup]string)
+	enums := p.sortedEnums()
+	for _, group := range enums {
+		enumURLs[group] = slug("enums", qualify(group.Project, group.Package), enumTypeName(group))
+	}
+	tableURLs := make(map[string]string)
+	for _, name := range sortedTableNames(p.dbComments) {
+		tableURLs[name] = slug("tables", p.dbComments[name].Schema, name)
+	}
+	schemaURLs := make(map[string]string)
+	for _, name := range sortedTableNames(p.apiSchemas) {
+		schemaURLs[name] = slug("schemas", p.apiSchemas[name].Schema, name)
+	}
+
+	// 列与枚举的双向链接
+	columnEnums := make(map[string]*siteLink)
+	enumColumns := make(map[*EnumGroup][]siteLink)
+	for _, ec := range p.enumColumns() {
+		columnEnums[ec.Key+"."+ec.Column] = &siteLink{Title: enumTypeName(ec.Group), URL: enumURLs[ec.Group]}
+		link := siteLink{Title: ec.Table + "." + ec.Column}
+		if url, ok := tableURLs[ec.Key]; ok {
+			link.URL = url + "#" + ec.Column
+		}
+		enumColumns[ec.Group] = append(enumColumns[ec.Group], link)
+	}
+
+	// 侧边栏：枚举按包分组，数据表和接口数据模型按 schema 分组
+	var nav []siteNavSection
+	var search []siteSearchEntry
+
+	enumSection := siteNavSection{Title: "枚举"}
+	pkgs, byPkg := groupsByPackage(enums)
+	for _, pkg := range pkgs {
+		navGroup := siteNavGroup{Name: pkg}
+		for _, group := range byPkg[pkg] {
+			navGroup.Links = append(navGroup.Links, siteLink{
+				Title: enumTypeName(group), URL: enumURLs[group], Description: oneLine(group.Description),
+			})
+			search = append(search, enumSearchEntry(group, enumURLs[group]))
+		}
+		enumSection.Groups = append(enumSection.Groups, navGroup)
+	}
+	if len(enumSection.Groups) > 0 {
+		nav = append(nav, enumSection)
+	}
+	for _, section := range []struct {
+		title  string
+		tables map[string]TableComment
+		urls   map[string]string
+	}{
+		{"数据表", p.dbComments, tableURLs},
+		{"接口数据模型", p.apiSchemas, schemaURLs},
+	} {
+		navSection := siteNavSection{Title: section.title}
+		bySchema := make(map[string][]siteLink)
+		var schemas []string
+		for _, name := range sortedTableNames(section.tables) {
+			table := section.tables[name]
+			schema := qualify(table.Project, table.Schema)
+			if _, ok := bySchema[schema]; !ok {
+				schemas = append(schemas, schema)
+			}
+			bySchema[schema] = append(bySchema[schema], siteLink{
+				Title: name, URL: section.urls[name], Description: table.Comment,
+			})
+			search = append(search, p.tableSearchEntry(section.title, name, table, section.urls[name]))
+		}
+		sort.Strings(schemas)
+		for _, schema := range schemas {
+			navSection.Groups = append(navSection.Groups, siteNavGroup{Name: orDash(schema), Links: bySchema[schema]})
+		}
+		if len(navSection.Groups) > 0 {
+			nav = append(nav, navSection)
+		}
+	}
+
+	render := func(kind, path string, page sitePage) error {
+		page.Project = project
+		page.Current = path
+		page.Root = strings.Repeat("../", strings.Count(path, "/"))
+		page.Nav = openNavGroup(nav, path)
+
+		var buf bytes.Buffer
+		if err := siteTemplates[kind].ExecuteTemplate(&buf, "layout", page); err != nil {
+			return fmt.Errorf("渲染 %s 失败: %w", path, err)
+		}
+		files[path] = buf.Bytes()
+		return nil
+	}
+
+	// 首页
+	index := siteIndex{Categories: p.categoryCounts(), EnumCount: len(enums), TableCount: len(p.dbComments), SchemaCount: len(p.apiSchemas)}
+	if err := render("index", "index.html", sitePage{Title: "首页", Index: index}); err != nil {
+		return nil, err
+	}
+
+	// 枚举页面
+	for _, group := range enums {
+		page := sitePage{
+			Title: enumTypeName(group),
+			Enum:  &siteEnum{TypeName: enumTypeName(group), Group: group, Columns: enumColumns[group]},
+		}
+		if err := render("enum", enumURLs[group], page); err != nil {
+			return nil, err
+		}
+	}
+
+	// 数据表和接口数据模型页面，只有数据表字段会关联枚举
+	for _, name := range sortedTableNames(p.dbComments) {
+		table := p.dbComments[name]
+		st := &siteTable{Kind: "数据表", SchemaLabel: "Schema", Table: table}
+		for _, field := range table.Fields {
+			st.Fields = append(st.Fields, siteField{FieldComment: field, Enum: columnEnums[name+"."+field.FieldName]})
+		}
+		if err := render("table", tableURLs[name], sitePage{Title: name, Table: st}); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedTableNames(p.apiSchemas) {
+		schema := p.apiSchemas[name]
+		st := &siteTable{Kind: "接口数据模型", SchemaLabel: "接口文档", Table: schema}
+		for _, field := range schema.Fields {
+			st.Fields = append(st.Fields, siteField{FieldComment: field})
+		}
+		if err := render("table", schemaURLs[name], sitePage{Title: name, Table: st}); err != nil {
+			return nil, err
+		}
+	}
+
+	// 静态资源和搜索索引
+	for _, asset := range []string{"style.css", "search.js"} {
+		data, err := siteFS.ReadFile("site/" + asset)
+		if err != nil {
+			return nil, err
+		}
+		files["assets/"+asset] = data
+	}
+	indexJSON, err := json.Marshal(search)
+	if err != nil {
+		return nil, fmt.Errorf("生成搜索索引失败: %w", err)
+	}
+	files["assets/search-index.js"] = []byte("window.DOCGEN_SEARCH_INDEX = " + string(indexJSON) + ";\n")
+
+	return files, nil
+}
+
+// 当前页面所在的分组默认展开
+func openNavGroup(nav []siteNavSection, current string) []siteNavSection {
+	result := make([]siteNavSection, len(nav))
+	for i, section := range nav {
+		result[i] = siteNavSection{Title: section.Title}
+		for _, group := range section.Groups {
+			group.Open = false
+			for _, link := range group.Links {
+				if link.URL == current {
+					group.Open = true
+				}
+			}
+			result[i].Groups = append(result[i].Groups, group)
+		}
+	}
+	return result
+}
+
+func enumSearchEntry(group *EnumGroup, url string) siteSearchEntry {
+	var text []string
+	for _, item := range group.Items {
+		text = append(text, item.Name, valueText(item.Value), item.Comment)
+	}
+	// 索引中的 tags 始终是数组，search.js 不用处理 null
+	tags := []string{}
+	tags = append(tags, group.Tags...)
+	return siteSearchEntry{
+		Title:   enumTypeName(group),
+		Kind:    "枚举 · " + qualify(group.Project, group.Package),
+		URL:     url,
+		Tags:    tags,
+		Summary: oneLine(group.Description),
+		Text:    strings.Join(text, " "),
+	}
+}
+
+// 数据表的标签包括字段名以及表和字段注释中的关键词
+func (p *Parser) tableSearchEntry(kind, name string, table TableComment, url string) siteSearchEntry {
+	tags := []string{}
+	tags = append(tags, p.segmenter.Keywords(table.Comment)...)
+	var text []string
+	for _, field := range table.Fields {
+		tags = append(tags, strings.ToLower(field.FieldName))
+		tags = append(tags, p.segmenter.Keywords(field.Comment)...)
+		text = append(text, field.FieldName, field.Comment)
+	}
+	return siteSearchEntry{
+		Title:   name,
+		Kind:    kind + " · " + qualify(table.Project, table.Schema),
+		URL:     url,
+		Tags:    tags,
+		Summary: table.Comment,
+		Text:    strings.Join(text, " "),
+	}
+}
+
+func sortedTableNames(tables map[string]TableComment) []string {
+	var names []string
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,65 @@
+// 基于 search-index.js 的离线搜索：所有关键词都命中名称、标签或注释时展示结果
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  var content = document.getElementById("content");
+  var index = window.DOCGEN_SEARCH_INDEX || [];
+
+  function escapeHTML(s) {
+    return s.replace(/[&<>"']/g, function (c) {
+      return { "&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;" }[c];
+    });
+  }
+
+  function score(entry, terms) {
+    var total = 0;
+    for (var i = 0; i < terms.length; i++) {
+      var t = terms[i];
+      if (entry.title.toLowerCase().indexOf(t) >= 0) {
+        total += 10;
+      } else if ((entry.tags || []).some(function (tag) { return tag.indexOf(t) >= 0; })) {
+        total += 5;
+      } else if (entry.text.toLowerCase().indexOf(t) >= 0) {
+        total += 1;
+      } else {
+        return 0;
+      }
+    }
+    return total;
+  }
+
+  function search() {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    if (terms.length === 0) {
+      results.hidden = true;
+      content.hidden = false;
+      return;
+    }
+    var matches = index
+      .map(function (entry) { return { entry: entry, score: score(entry, terms) }; })
+      .filter(function (m) { return m.score > 0; })
+      .sort(function (a, b) { return b.score - a.score || a.entry.title.localeCompare(b.entry.title); })
+      .slice(0, 50);
+
+    var html = "<h1>搜索结果</h1>";
+    if (matches.length === 0) {
+      html += "<p>没有找到匹配的内容</p>";
+    } else {
+      html += "<ul>" + matches.map(function (m) {
+        return '<li><a href="' + DOCGEN_ROOT + m.entry.url + '">' + escapeHTML(m.entry.title) + "</a> " +
+          '<span class="hint">' + escapeHTML(m.entry.kind) + "</span><br>" + escapeHTML(m.entry.summary) + "</li>";
+      }).join("") + "</ul>";
+    }
+    results.innerHTML = html;
+    results.hidden = false;
+    content.hidden = true;
+  }
+
+  input.addEventListener("input", search);
+  input.addEventListener("keydown", function (e) {
+    if (e.key === "Escape") {
+      input.value = "";
+      search();
+    }
+  });
+})();
@@ -0,0 +1,411 @@
+package docgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 每个枚举项保留的使用示例数量
+const maxUsageExamples = 3
+
+// 引用类型，按代表性从高到低排列
+const (
+	UsageCase      = "case"      // switch 分支
+	UsageAssign    = "assign"    // 赋值或结构体字段设置
+	UsageCompare   = "compare"   // 相等/不等比较
+	UsageReturn    = "return"    // 作为返回值
+	UsageArgument  = "argument"  // 作为函数调用参数
+	UsageReference = "reference" // 其他引用
+)
+
+var usageKindRank = map[string]int{
+	UsageCase:      0,
+	UsageAssign:    1,
+	UsageCompare:   2,
+	UsageReturn:    3,
+	UsageArgument:  4,
+	UsageReference: 5,
+}
+
+var usageKindLabels = map[string]string{
+	UsageCase:      "分支处理",
+	UsageAssign:    "赋值",
+	UsageCompare:   "比较",
+	UsageReturn:    "返回",
+	UsageArgument:  "调用参数",
+	UsageReference: "引用",
+}
+
+// EnumUsage 表示枚举项在代码中的一处引用
+type EnumUsage struct {
+	File     string `json:"file"`     // 文件路径（相对项目根目录）
+	Line     int    `json:"line"`     // 行号
+	Function string `json:"function"` // 所在函数
+	Kind     string `json:"kind"`     // 引用类型
+	Snippet  string `json:"snippet"`  // 代码片段
+}
+
+// ParseEnumUsages 扫描项目中对 @ai 枚举常量的引用，填充使用示例和引用次数，
+// 需要在 ParseEnums 之后调用
+func (p *Parser) ParseEnumUsages(rootPath string) (map[string]*EnumGroup, error) {
+	// 按“包目录.常量名”索引枚举项，不同包的同名常量互不影响
+	index := &usageIndex{items: make(map[string][]*EnumItem), dirs: make(map[string]bool)}
+	for _, group := range p.enums {
+		if !strings.HasSuffix(group.File, ".go") {
+			continue
+		}
+		group.UsagesScanned = true
+		dir := path.Dir(group.File)
+		index.dirs[dir] = true
+		for i := range group.Items {
+			item := &group.Items[i]
+			item.Usages = nil
+			item.UsageCount = 0
+			key := usageKey(dir, item.Name)
+			index.items[key] = append(index.items[key], item)
+		}
+	}
+	p.usagesScanned = true
+	if len(index.items) == 0 {
+		return p.enums, nil
+	}
+
+	err := p.walkFiles(rootPath, ".go", func(path string) error {
+		return p.scanUsages(rootPath, path, index)
+	})
+	if err != nil {
+		return p.enums, err
+	}
+
+	for _, items := range index.items {
+		for _, item := range items {
+			item.UsageCount = len(item.Usages)
+			item.Unused = item.UsageCount == 0
+			item.Usages = pickUsageExamples(item.Usages)
+			if len(item.Usages) > 0 {
+				item.Example = item.Usages[0].String()
+			}
+		}
+	}
+
+	return p.enums, nil
+}
+
+// 枚举项的引用索引
+type usageIndex struct {
+	items map[string][]*EnumItem // 包目录.常量名 -> 枚举项
+	dirs  map[string]bool        // 定义了枚举的包目录（相对仓库根目录）
+}
+
+func usageKey(dir, name string) string {
+	return dir + "." + name
+}
+
+// 找出导入路径对应的包目录：目录与导入路径末尾相同的段数最多者，
+// 如 enum_tools/example 对应 example，仓库根目录与模块根目录不同时同样适用
+func (idx *usageIndex) importDirs(importPath string) []string {
+	var dirs []string
+	best := 0
+	for dir := range idx.dirs {
+		n := commonSuffixSegments(dir, importPath)
+		if n == 0 || n < best {
+			continue
+		}
+		if n > best {
+			best, dirs = n, nil
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// 两个斜杠分隔的路径末尾相同的段数
+func commonSuffixSegments(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[len(as)-1-n] == bs[len(bs)-1-n] && as[len(as)-1-n] != "." {
+		n++
+	}
+	return n
+}
+
+// 文件中导入的包名到导入路径的映射，没有别名时以路径最后一段为包名
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+func (p *Parser) scanUsages(rootPath, filename string, index *usageIndex) error {
+	node, err := p.parseGoFile(filename)
+	if err != nil {
+		return err
+	}
+	// 生成代码中的引用不代表业务使用
+	if ast.IsGenerated(node) {
+		return nil
+	}
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(src), "\n")
+	relPath := p.relPath(rootPath, filename)
+	fileDir := path.Dir(relPath)
+	imports := fileImports(node)
+
+	// 声明位置本身不算引用
+	declared := make(map[*ast.Ident]bool)
+	for _, decl := range node.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && (gen.Tok == token.CONST || gen.Tok == token.VAR) {
+			for _, spec := range gen.Specs {
+				if vspec, ok := spec.(*ast.ValueSpec); ok {
+					for _, name := range vspec.Names {
+						declared[name] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, decl := range node.Decls {
+		funcName := ""
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			funcName = funcDeclName(fn)
+		}
+
+		var stack []ast.Node
+		ast.Inspect(decl, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+
+			if ident, ok := n.(*ast.Ident); ok && !declared[ident] && isEnumReference(ident, stack) {
+				var items []*EnumItem
+				if sel, ok := stack[len(stack)-1].(*ast.SelectorExpr); ok && sel.Sel == ident {
+					// pkg.Name 按导入路径找到定义的包
+					pkgName := sel.X.(*ast.Ident).Name
+					if importPath, ok := imports[pkgName]; ok {
+						for _, dir := range index.importDirs(importPath) {
+							items = append(items, index.items[usageKey(dir, ident.Name)]...)
+						}
+					}
+				} else {
+					// 未限定的名称引用同一个包中的常量
+					items = index.items[usageKey(fileDir, ident.Name)]
+				}
+				if len(items) > 0 {
+					line := p.fset.Position(ident.Pos()).Line
+					usage := EnumUsage{
+						File:     relPath,
+						Line:     line,
+						Function: funcName,
+						Kind:     classifyUsage(stack),
+					}
+					if line-1 < len(lines) {
+						usage.Snippet = strings.TrimSpace(lines[line-1])
+					}
+					for _, item := range items {
+						item.Usages = append(item.Usages, usage)
+					}
+				}
+			}
+
+			stack = append(stack, n)
+			return true
+		})
+	}
+
+	return nil
+}
+
+// 过滤掉不是对常量本身的引用，如结构体字段名、选择器右侧的方法名等
+func isEnumReference(ident *ast.Ident, stack []ast.Node) bool {
+	if len(stack) == 0 {
+		return false
+	}
+	switch parent := stack[len(stack)-1].(type) {
+	case *ast.SelectorExpr:
+		// 接受 MailStatusFailed.String() 中的常量，以及 pkg.Name 形式
+		if parent.X == ident {
+			return true
+		}
+		_, ok := parent.X.(*ast.Ident)
+		return ok
+	case *ast.Field, *ast.FuncDecl, *ast.TypeSpec, *ast.LabeledStmt, *ast.BranchStmt, *ast.ImportSpec:
+		return false
+	case *ast.ValueSpec:
+		for _, name := range parent.Names {
+			if name == ident {
+				return false
+			}
+		}
+	case *ast.AssignStmt:
+		if parent.Tok == token.DEFINE {
+			for _, lhs := range parent.Lhs {
+				if lhs == ident {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// 根据最近的父节点判断引用类型
+func classifyUsage(stack []ast.Node) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.SelectorExpr, *ast.ParenExpr:
+			continue
+		case *ast.CaseClause:
+			return UsageCase
+		case *ast.AssignStmt, *ast.KeyValueExpr, *ast.ValueSpec:
+			return UsageAssign
+		case *ast.BinaryExpr:
+			if n.Op == token.EQL || n.Op == token.NEQ {
+				return UsageCompare
+			}
+			return UsageReference
+		case *ast.ReturnStmt:
+			return UsageReturn
+		case *ast.CallExpr:
+			return UsageArgument
+		default:
+			return UsageReference
+		}
+	}
+	return UsageReference
+}
+
+// 优先挑选不同引用类型的示例，同类型按位置排序
+func pickUsageExamples(usages []EnumUsage) []EnumUsage {
+	sort.SliceStable(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if usageKindRank[a.Kind] != usageKindRank[b.Kind] {
+			return usageKindRank[a.Kind] < usageKindRank[b.Kind]
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	var picked, rest []EnumUsage
+	seenKinds := make(map[string]bool)
+	for _, usage := range usages {
+		if !seenKinds[usage.Kind] && len(picked) < maxUsageExamples {
+			seenKinds[usage.Kind] = true
+			picked = append(picked, usage)
+		} else {
+			rest = append(rest, usage)
+		}
+	}
+	for _, usage := range rest {
+		if len(picked) >= maxUsageExamples {
+			break
+		}
+		picked = append(picked, usage)
+	}
+	return picked
+}
+
+// String 返回 file:line 形式的引用描述
+func (u EnumUsage) String() string {
+	if u.Function != "" {
+		return fmt.Sprintf("%s:%d (%s) %s", u.File, u.Line, u.Function, u.Snippet)
+	}
+	return fmt.Sprintf("%s:%d %s", u.File, u.Line, u.Snippet)
+}
+
+// 获取函数名，方法带上接收者类型
+func funcDeclName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if index, ok := recv.(*ast.IndexExpr); ok {
+		recv = index.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// 遍历目录下指定后缀的文件，跳过隐藏目录和 vendor，并按配置的 include/exclude 过滤
+func (p *Parser) walkFiles(rootPath, ext string, fn func(path string) error) error {
+	return filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != rootPath && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ext) && p.included(rootPath, path) {
+			return fn(path)
+		}
+		return nil
+	})
+}
+
+// 输出枚举项的使用示例
+func writeUsageExamples(md *strings.Builder, enum *EnumGroup) {
+	var hasUsage bool
+	for _, item := range enum.Items {
+		if len(item.Usages) > 0 || item.Unused {
+			hasUsage = true
+			break
+		}
+	}
+	if !hasUsage {
+		return
+	}
+
+	md.WriteString("**使用示例：**\n\n")
+	for _, item := range enum.Items {
+		if item.Unused {
+			md.WriteString(fmt.Sprintf("- `%s`：未找到引用\n", item.Name))
+			continue
+		}
+		md.WriteString(fmt.Sprintf("- `%s`（共 %d 处引用）\n", item.Name, item.UsageCount))
+		for _, usage := range item.Usages {
+			location := fmt.Sprintf("%s:%d", usage.File, usage.Line)
+			if usage.Function != "" {
+				location += " " + usage.Function
+			}
+			md.WriteString(fmt.Sprintf("  - %s `%s`：`%s`\n",
+				usageKindLabels[usage.Kind], location, usage.Snippet))
+		}
+	}
+	md.WriteString("\n")
+}
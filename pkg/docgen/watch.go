@@ -0,0 +1,258 @@
+package docgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 监听模式的默认轮询间隔和防抖时间
+const (
+	DefaultWatchInterval = 500 * time.Millisecond
+	DefaultWatchDebounce = 300 * time.Millisecond
+)
+
+// 摘要中每类变化最多列出的实体数
+const maxChangeNames = 5
+
+type cachedAST struct {
+	file    *ast.File
+	modTime time.Time
+	size    int64
+}
+
+// fileStamp 用于判断文件是否变化
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// WatchOptions 监听配置
+type WatchOptions struct {
+	Extensions []string      // 关注的文件后缀，如 .go、.sql
+	Interval   time.Duration // 轮询间隔
+	Debounce   time.Duration // 最后一次变化后等待的时间，连续保存只触发一次
+}
+
+// Watch 轮询 rootPath 下的文件，发现变化并稳定 Debounce 时间后调用 onChange，
+// changed 为新增、修改或删除的文件。ctx 取消时返回 nil
+func (p *Parser) Watch(ctx context.Context, rootPath string, opts WatchOptions, onChange func(changed []string)) error {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWatchInterval
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultWatchDebounce
+	}
+
+	prev, err := p.snapshot(rootPath, opts.Extensions)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	pending := make(map[string]bool)
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		cur, err := p.snapshot(rootPath, opts.Extensions)
+		if err != nil {
+			// 保存过程中文件可能短暂不存在，下次轮询再试
+			fmt.Printf("警告: 扫描文件失败: %v\n", err)
+			continue
+		}
+		if changed := diffSnapshots(prev, cur); len(changed) > 0 {
+			for _, path := range changed {
+				pending[path] = true
+			}
+			lastChange = time.Now()
+			prev = cur
+			continue
+		}
+
+		if len(pending) > 0 && time.Since(lastChange) >= opts.Debounce {
+			changed := make([]string, 0, len(pending))
+			for path := range pending {
+				changed = append(changed, path)
+			}
+			sort.Strings(changed)
+			pending = make(map[string]bool)
+			onChange(changed)
+		}
+	}
+}
+
+// 记录目录下关注文件的修改时间和大小
+func (p *Parser) snapshot(rootPath string, exts []string) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	err := p.walkFiles(rootPath, "", func(path string) error {
+		if !containsString(exts, strings.ToLower(filepath.Ext(path))) {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return stamps, err
+}
+
+func diffSnapshots(prev, cur map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range cur {
+		if old, ok := prev[path]; !ok || !old.modTime.Equal(stamp.modTime) || old.size != stamp.size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := cur[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// Reset 清空解析结果以便重新执行提取器，保留语法树缓存，
+// 未修改的 Go 文件不会重新解析。监听模式下每次变化都会重新执行全部提取器，
+// 而不只是处理变化的文件，大型仓库中每轮耗时与全量扫描相当
+func (p *Parser) Reset() {
+	p.enums = make(map[string]*EnumGroup)
+	p.dbComments = make(map[string]TableComment)
+	p.apiSchemas = make(map[string]TableComment)
+	p.errorCodes = nil
+	p.routes = nil
+	p.configStructs = nil
+	p.usagesScanned = false
+}
+
+// Invalidate 丢弃指定文件的语法树缓存
+func (p *Parser) Invalidate(files []string) {
+	for _, file := range files {
+		p.forget(file)
+	}
+}
+
+// 丢弃文件的语法树缓存，并把旧文件从 FileSet 中移除，
+// 避免监听模式下反复解析同一文件使 FileSet 无限增长
+func (p *Parser) forget(filename string) {
+	cached, ok := p.astCache[filename]
+	if !ok {
+		return
+	}
+	if file := p.fset.File(cached.file.Package); file != nil {
+		p.fset.RemoveFile(file)
+	}
+	delete(p.astCache, filename)
+}
+
+// Fingerprints 返回每个实体的内容摘要，用于比较两次解析之间的变化
+func (p *Parser) Fingerprints() map[string]string {
+	prints := make(map[string]string)
+	add := func(key string, v interface{}) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			data = []byte(fmt.Sprintf("%+v", v))
+		}
+		prints[key] = string(data)
+	}
+
+	for key, group := range p.enums {
+		name := qualify(group.Project, enumTypeName(group))
+		// 与 Go 枚举同名而单独保存的 OpenAPI 枚举
+		if strings.HasSuffix(key, "@"+group.File) {
+			name += "@" + group.File
+		}
+		add("枚举 "+name, group)
+	}
+	for name, table := range p.dbComments {
+		add("数据表 "+name, table)
+	}
+	for name, schema := range p.apiSchemas {
+		add("接口数据模型 "+name, schema)
+	}
+	for _, code := range p.errorCodes {
+		add("错误码 "+qualify(code.Package, code.Name), code)
+	}
+	for _, route := range p.routes {
+		add("路由 "+route.Method+" "+route.Path, route)
+	}
+	for _, cs := range p.configStructs {
+		add("配置 "+cs.Package+"."+cs.Name, cs)
+	}
+	return prints
+}
+
+// EntityChanges 两次解析之间新增、修改和删除的实体
+type EntityChanges struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+// DiffFingerprints 比较两次解析的实体摘要
+func DiffFingerprints(before, after map[string]string) EntityChanges {
+	var changes EntityChanges
+	for key, print := range after {
+		if old, ok := before[key]; !ok {
+			changes.Added = append(changes.Added, key)
+		} else if old != print {
+			changes.Updated = append(changes.Updated, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changes.Removed = append(changes.Removed, key)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Updated)
+	sort.Strings(changes.Removed)
+	return changes
+}
+
+// Empty 判断是否没有变化
+func (c EntityChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// String 返回变化摘要，如“新增 枚举 OrderStatus；修改 数据表 orders”
+func (c EntityChanges) String() string {
+	var parts []string
+	for _, kind := range []struct {
+		label string
+		names []string
+	}{
+		{"新增", c.Added},
+		{"修改", c.Updated},
+		{"删除", c.Removed},
+	} {
+		if len(kind.names) == 0 {
+			continue
+		}
+		names := kind.names
+		suffix := ""
+		if len(names) > maxChangeNames {
+			suffix = fmt.Sprintf(" 等 %d 个", len(names))
+			names = names[:maxChangeNames]
+		}
+		parts = append(parts, kind.label+" "+strings.Join(names, "、")+suffix)
+	}
+	if len(parts) == 0 {
+		return "无变化"
+	}
+	return strings.Join(parts, "；")
+}
@@ -0,0 +1,104 @@
+package rag
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+// 来源摘录的最大字数
+const sourceExcerptRunes = 200
+
+// Answer 问答结果
+type Answer struct {
+	Text    string   // 回答正文，引用的上下文以 [n] 标注
+	Sources []Source // 回答引用的来源，按首次引用的顺序排列；回答没有标注引用时为全部上下文
+	Model   string   // 生成回答的模型，没有调用模型时为空
+}
+
+// Source 回答引用的一个上下文分块
+type Source struct {
+	Index       int     // 上下文编号，与回答中的 [n] 对应
+	Path        string  // 文档文件路径
+	Headings    string  // 分块所在的标题路径
+	Excerpt     string  // 分块内容摘录
+	Score       float32 // 检索得分，含义由 ScoreKind 决定
+	ScoreKind   string  // 得分类型：ScoreCosine、ScoreBM25、ScoreRRF 或 ScoreRerank
+	VectorScore float32 // 向量检索的余弦相似度，分块没有被向量检索召回时为 0
+}
+
+// Location 返回文件路径和标题路径，如“docs/knowledge.md > 数据库表 > order_details（订单详情表）”
+func (s Source) Location() string {
+	if s.Headings == "" {
+		return s.Path
+	}
+	return s.Path + " > " + s.Headings
+}
+
+// 把检索到的分块转换为编号的来源，编号从 1 开始
+func sourcesFromDocs(docs []schema.Document) []Source {
+	sources := make([]Source, len(docs))
+	for i, doc := range docs {
+		path, _ := doc.Metadata[MetaSource].(string)
+		headings, _ := doc.Metadata[MetaHeadings].(string)
+		kind, _ := doc.Metadata[scoreKindKey].(string)
+		vectorScore, _ := doc.Metadata[vectorScoreKey].(float32)
+		sources[i] = Source{
+			Index:       i + 1,
+			Path:        path,
+			Headings:    headings,
+			Excerpt:     excerpt(doc.PageContent, sourceExcerptRunes),
+			Score:       doc.Score,
+			ScoreKind:   kind,
+			VectorScore: vectorScore,
+		}
+	}
+	return sources
+}
+
+// 构建交给语言模型的上下文，每个分块前标注编号和来源
+func buildContext(sources []Source, docs []schema.Document) string {
+	var sb strings.Builder
+	for i, doc := range docs {
+		fmt.Fprintf(&sb, "[%d] 来源: %s\n%s\n\n", sources[i].Index, sources[i].Location(), doc.PageContent)
+	}
+	return sb.String()
+}
+
+// 匹配回答中的引用标注，如 [1]、[1][2]、[1, 3]
+var citationPattern = regexp.MustCompile(`\[(\d+(?:\s*[,，、]\s*\d+)*)\]`)
+
+// 找出回答引用的来源，按首次引用的顺序排列，忽略超出范围的编号。
+// 回答没有任何有效引用时返回全部来源，便于核对
+func citedSources(text string, sources []Source) []Source {
+	var cited []Source
+	seen := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(text, -1) {
+		for _, field := range strings.FieldsFunc(match[1], func(r rune) bool {
+			return r == ',' || r == '，' || r == '、' || r == ' '
+		}) {
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 || n > len(sources) || seen[n] {
+				continue
+			}
+			seen[n] = true
+			cited = append(cited, sources[n-1])
+		}
+	}
+	if len(cited) == 0 {
+		return sources
+	}
+	return cited
+}
+
+// 截取文本开头，连续的空白合并为一个空格
+func excerpt(text string, limit int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= limit {
+		return string(runes)
+	}
+	return string(runes[:limit]) + "……"
+}
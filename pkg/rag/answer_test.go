@@ -0,0 +1,36 @@
+package rag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCitedSources(t *testing.T) {
+	sources := []Source{{Index: 1}, {Index: 2}, {Index: 3}}
+	tests := []struct {
+		name string
+		text string
+		want []int
+	}{
+		{"单个引用", "订单状态有五种 [2]。", []int{2}},
+		{"按首次引用排序", "见 [3]，另见 [1][3]。", []int{3, 1}},
+		{"半角逗号", "[1, 3]", []int{1, 3}},
+		{"全角逗号", "[1，3]", []int{1, 3}},
+		{"顿号", "[3、2]", []int{3, 2}},
+		{"忽略超出范围的编号", "[0][4][2]", []int{2}},
+		{"只有超出范围的编号时返回全部来源", "[9]", []int{1, 2, 3}},
+		{"没有引用时返回全部来源", "没有标注引用的回答", []int{1, 2, 3}},
+		{"不是引用的方括号", "数组 a[i] 和 [x]", []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, s := range citedSources(tt.text, sources) {
+				got = append(got, s.Index)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("citedSources(%q) = %v，期望 %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
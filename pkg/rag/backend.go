@@ -0,0 +1,99 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// Backend 向量存储后端，只负责保存和检索向量，文本向量化由 VectorStore 完成。
+// 相似度统一为余弦相似度，越大越相近
+type Backend interface {
+	// CreateCollection 创建集合，已存在时不报错
+	CreateCollection(ctx context.Context, dim int) error
+	// Upsert 按 ID 写入点，ID 已存在时覆盖
+	Upsert(ctx context.Context, points []Point) error
+	// Delete 删除满足过滤条件的点，条件为空时不删除任何点
+	Delete(ctx context.Context, filter Filter) error
+	// Search 返回与 vector 最相近且满足过滤条件的 k 个点，按相似度从高到低排列
+	Search(ctx context.Context, vector []float32, k int, filter Filter) ([]ScoredPoint, error)
+	// Count 返回集合中点的数量
+	Count(ctx context.Context) (int, error)
+	// Drop 删除整个集合，集合不存在时不报错
+	Drop(ctx context.Context) error
+	// Flush 持久化尚未落盘的写入
+	Flush(ctx context.Context) error
+	// Close 释放连接等资源
+	Close() error
+}
+
+// Point 向量存储中的一个点，对应一个文档分块
+type Point struct {
+	ID       string
+	Vector   []float32
+	Content  string
+	Metadata map[string]interface{}
+}
+
+// ScoredPoint 检索结果，Score 为余弦相似度，不包含向量
+type ScoredPoint struct {
+	Point
+	Score float32
+}
+
+// Filter 元数据过滤条件：Match 中的每个键都需要相等，值为切片时匹配其中任意一个；
+// ExcludeIDs 中的点不满足条件
+type Filter struct {
+	Match      map[string]interface{}
+	ExcludeIDs []string
+}
+
+// Empty 判断是否没有任何条件
+func (f Filter) Empty() bool {
+	return len(f.Match) == 0 && len(f.ExcludeIDs) == 0
+}
+
+// 判断点是否满足过滤条件，供不支持服务端过滤的后端使用
+func (f Filter) matches(id string, metadata map[string]interface{}) bool {
+	for _, excluded := range f.ExcludeIDs {
+		if excluded == id {
+			return false
+		}
+	}
+	return matchFilters(metadata, f.Match)
+}
+
+// NewBackend 按配置的类型创建向量存储后端
+func NewBackend(config VectorStoreConfig) (Backend, error) {
+	if config.CollectionName == "" {
+		config.CollectionName = "default_collection"
+	}
+
+	switch config.Type {
+	case "", VectorStoreQdrant:
+		url := config.URL
+		if url == "" {
+			url = "http://localhost:6333"
+		}
+		return NewQdrantBackend(url, config.CollectionName), nil
+	case VectorStoreChroma:
+		url := config.URL
+		if url == "" {
+			url = "http://localhost:8000"
+		}
+		return NewChromaBackend(url, config.CollectionName), nil
+	case VectorStorePGVector:
+		return NewPGVectorBackend(config.Driver, config.DSN, config.CollectionName)
+	case VectorStoreLocal:
+		if config.PersistDir == "" {
+			return nil, fmt.Errorf("本地向量存储需要设置持久化目录")
+		}
+		return NewLocalStore(LocalStoreConfig{
+			Path:  filepath.Join(config.PersistDir, config.CollectionName+".gob"),
+			Index: config.Index,
+			HNSW:  config.HNSW,
+		})
+	default:
+		return nil, fmt.Errorf("不支持的向量存储类型: %s", config.Type)
+	}
+}
@@ -0,0 +1,321 @@
+// Package backendtest 是向量存储后端的契约检查，rag.Backend 的每个实现都应当全部通过。
+//
+// 检查会反复删除并重建集合，只能对专用于检查的集合运行。
+package backendtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/google/uuid"
+
+	"enum_tools/pkg/rag"
+)
+
+// 检查使用的向量维度
+const dim = 8
+
+// 生成检查用点 ID 的命名空间，部分后端要求 ID 为 UUID
+var idNamespace = uuid.MustParse("0b6c9a52-7f3e-4d1a-9c2b-5e8f1a3d6c70")
+
+// Result 一项检查的结果，Err 为空表示通过
+type Result struct {
+	Name string
+	Err  error
+}
+
+type check struct {
+	name string
+	run  func(ctx context.Context, b rag.Backend) error
+}
+
+var checks = []check{
+	{"重复创建集合", checkCreateTwice},
+	{"写入与计数", checkUpsertCount},
+	{"按 ID 覆盖", checkOverwrite},
+	{"检索排序与得分", checkSearchOrder},
+	{"正文与元数据", checkPayload},
+	{"等值过滤", checkFilterEqual},
+	{"多值过滤", checkFilterAny},
+	{"按条件删除并排除 ID", checkDeleteExclude},
+	{"空条件不删除", checkDeleteEmpty},
+	{"删除集合", checkDrop},
+}
+
+// Run 依次执行所有检查，每项检查前清空集合，结束后删除集合
+func Run(ctx context.Context, b rag.Backend) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		err := reset(ctx, b)
+		if err == nil {
+			err = c.run(ctx, b)
+		}
+		results = append(results, Result{Name: c.name, Err: err})
+	}
+	if err := b.Drop(ctx); err != nil {
+		results = append(results, Result{Name: "清理集合", Err: err})
+	}
+	return results
+}
+
+func reset(ctx context.Context, b rag.Backend) error {
+	if err := b.Drop(ctx); err != nil {
+		return fmt.Errorf("删除集合: %w", err)
+	}
+	if err := b.CreateCollection(ctx, dim); err != nil {
+		return fmt.Errorf("创建集合: %w", err)
+	}
+	return nil
+}
+
+// 测试点：向量主要落在第 n 维，相邻维度有少量分量，彼此的相似度明显不同
+func point(n int, source string) rag.Point {
+	vector := make([]float32, dim)
+	vector[n%dim] = 1
+	vector[(n+1)%dim] = 0.2
+	return rag.Point{
+		ID:       id(n),
+		Vector:   vector,
+		Content:  fmt.Sprintf("文档分块 %d", n),
+		Metadata: map[string]interface{}{"source": source, "chunk": n},
+	}
+}
+
+func id(n int) string {
+	return uuid.NewSHA1(idNamespace, []byte(fmt.Sprint(n))).String()
+}
+
+func upsert(ctx context.Context, b rag.Backend, points ...rag.Point) error {
+	if err := b.Upsert(ctx, points); err != nil {
+		return fmt.Errorf("写入: %w", err)
+	}
+	return nil
+}
+
+func expectCount(ctx context.Context, b rag.Backend, want int) error {
+	got, err := b.Count(ctx)
+	if err != nil {
+		return fmt.Errorf("计数: %w", err)
+	}
+	if got != want {
+		return fmt.Errorf("点的数量为 %d，期望 %d", got, want)
+	}
+	return nil
+}
+
+// 检索并返回结果的 ID
+func searchIDs(ctx context.Context, b rag.Backend, n, k int, filter rag.Filter) ([]string, error) {
+	results, err := b.Search(ctx, point(n, "").Vector, k, filter)
+	if err != nil {
+		return nil, fmt.Errorf("检索: %w", err)
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids, nil
+}
+
+// 比较两组 ID，忽略顺序
+func sameIDs(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	set := make(map[string]bool, len(want))
+	for _, id := range want {
+		set[id] = true
+	}
+	for _, id := range got {
+		if !set[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func checkCreateTwice(ctx context.Context, b rag.Backend) error {
+	if err := b.CreateCollection(ctx, dim); err != nil {
+		return fmt.Errorf("集合已存在时再次创建应当成功: %w", err)
+	}
+	return expectCount(ctx, b, 0)
+}
+
+func checkUpsertCount(ctx context.Context, b rag.Backend) error {
+	if err := upsert(ctx, b, point(0, "a"), point(1, "a"), point(2, "b")); err != nil {
+		return err
+	}
+	return expectCount(ctx, b, 3)
+}
+
+func checkOverwrite(ctx context.Context, b rag.Backend) error {
+	if err := upsert(ctx, b, point(0, "a"), point(1, "a")); err != nil {
+		return err
+	}
+	updated := point(0, "a")
+	updated.Content = "更新后的分块"
+	if err := upsert(ctx, b, updated); err != nil {
+		return err
+	}
+	if err := expectCount(ctx, b, 2); err != nil {
+		return err
+	}
+
+	results, err := b.Search(ctx, updated.Vector, 1, rag.Filter{})
+	if err != nil {
+		return fmt.Errorf("检索: %w", err)
+	}
+	if len(results) != 1 || results[0].Content != updated.Content {
+		return fmt.Errorf("覆盖后检索到 %+v，期望正文为 %q", results, updated.Content)
+	}
+	return nil
+}
+
+func checkSearchOrder(ctx context.Context, b rag.Backend) error {
+	var points []rag.Point
+	for n := 0; n < 6; n++ {
+		points = append(points, point(n, "a"))
+	}
+	if err := upsert(ctx, b, points...); err != nil {
+		return err
+	}
+
+	results, err := b.Search(ctx, points[2].Vector, 3, rag.Filter{})
+	if err != nil {
+		return fmt.Errorf("检索: %w", err)
+	}
+	if len(results) != 3 {
+		return fmt.Errorf("返回 %d 个结果，期望 3 个", len(results))
+	}
+	if results[0].ID != id(2) {
+		return fmt.Errorf("最相近的结果为 %s，期望 %s", results[0].ID, id(2))
+	}
+	if math.Abs(float64(results[0].Score)-1) > 1e-3 {
+		return fmt.Errorf("相同向量的得分为 %f，期望为余弦相似度 1", results[0].Score)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			return fmt.Errorf("结果未按得分从高到低排列: %f 在 %f 之后", results[i].Score, results[i-1].Score)
+		}
+	}
+
+	if results, err := b.Search(ctx, points[0].Vector, 10, rag.Filter{}); err != nil {
+		return fmt.Errorf("检索: %w", err)
+	} else if len(results) != len(points) {
+		return fmt.Errorf("k 大于点的数量时返回 %d 个结果，期望 %d 个", len(results), len(points))
+	}
+	return nil
+}
+
+func checkPayload(ctx context.Context, b rag.Backend) error {
+	p := point(3, "docs/订单.md")
+	p.Metadata["title"] = "订单状态"
+	if err := upsert(ctx, b, p); err != nil {
+		return err
+	}
+
+	results, err := b.Search(ctx, p.Vector, 1, rag.Filter{})
+	if err != nil {
+		return fmt.Errorf("检索: %w", err)
+	}
+	if len(results) != 1 {
+		return fmt.Errorf("返回 %d 个结果，期望 1 个", len(results))
+	}
+	got := results[0]
+	if got.ID != p.ID || got.Content != p.Content {
+		return fmt.Errorf("检索到 ID %s、正文 %q，期望 %s、%q", got.ID, got.Content, p.ID, p.Content)
+	}
+	// 经过 JSON 的后端会把整数转为浮点数，按 JSON 形式比较
+	if !reflect.DeepEqual(jsonValue(got.Metadata), jsonValue(p.Metadata)) {
+		return fmt.Errorf("元数据为 %v，期望 %v", got.Metadata, p.Metadata)
+	}
+	return nil
+}
+
+func jsonValue(v interface{}) interface{} {
+	data, _ := json.Marshal(v)
+	var out interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func checkFilterEqual(ctx context.Context, b rag.Backend) error {
+	if err := upsert(ctx, b, point(0, "a"), point(1, "b"), point(2, "a"), point(3, "c")); err != nil {
+		return err
+	}
+	ids, err := searchIDs(ctx, b, 1, 10, rag.Filter{Match: map[string]interface{}{"source": "a"}})
+	if err != nil {
+		return err
+	}
+	if !sameIDs(ids, id(0), id(2)) {
+		return fmt.Errorf("过滤 source=a 返回 %v，期望 %v", ids, []string{id(0), id(2)})
+	}
+	return nil
+}
+
+func checkFilterAny(ctx context.Context, b rag.Backend) error {
+	if err := upsert(ctx, b, point(0, "a"), point(1, "b"), point(2, "c")); err != nil {
+		return err
+	}
+	filter := rag.Filter{Match: map[string]interface{}{"source": []interface{}{"a", "c"}}}
+	ids, err := searchIDs(ctx, b, 1, 10, filter)
+	if err != nil {
+		return err
+	}
+	if !sameIDs(ids, id(0), id(2)) {
+		return fmt.Errorf("过滤 source in (a, c) 返回 %v，期望 %v", ids, []string{id(0), id(2)})
+	}
+	return nil
+}
+
+func checkDeleteExclude(ctx context.Context, b rag.Backend) error {
+	if err := upsert(ctx, b, point(0, "a"), point(1, "a"), point(2, "a"), point(3, "b")); err != nil {
+		return err
+	}
+	filter := rag.Filter{Match: map[string]interface{}{"source": "a"}, ExcludeIDs: []string{id(1)}}
+	if err := b.Delete(ctx, filter); err != nil {
+		return fmt.Errorf("删除: %w", err)
+	}
+	if err := expectCount(ctx, b, 2); err != nil {
+		return err
+	}
+	ids, err := searchIDs(ctx, b, 0, 10, rag.Filter{})
+	if err != nil {
+		return err
+	}
+	if !sameIDs(ids, id(1), id(3)) {
+		return fmt.Errorf("删除后剩余 %v，期望 %v", ids, []string{id(1), id(3)})
+	}
+	return nil
+}
+
+func checkDeleteEmpty(ctx context.Context, b rag.Backend) error {
+	if err := upsert(ctx, b, point(0, "a"), point(1, "b")); err != nil {
+		return err
+	}
+	if err := b.Delete(ctx, rag.Filter{}); err != nil {
+		return fmt.Errorf("删除: %w", err)
+	}
+	return expectCount(ctx, b, 2)
+}
+
+func checkDrop(ctx context.Context, b rag.Backend) error {
+	if err := upsert(ctx, b, point(0, "a")); err != nil {
+		return err
+	}
+	if err := b.Flush(ctx); err != nil {
+		return fmt.Errorf("持久化: %w", err)
+	}
+	if err := b.Drop(ctx); err != nil {
+		return fmt.Errorf("删除集合: %w", err)
+	}
+	if err := b.Drop(ctx); err != nil {
+		return fmt.Errorf("集合不存在时删除应当成功: %w", err)
+	}
+	if err := b.CreateCollection(ctx, dim); err != nil {
+		return fmt.Errorf("重新创建集合: %w", err)
+	}
+	return expectCount(ctx, b, 0)
+}
@@ -0,0 +1,279 @@
+package rag
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"enum_tools/pkg/segment"
+)
+
+// BM25 参数：k1 控制词频饱和速度，b 控制文档长度归一化的程度
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// BM25 索引文件的格式版本，分词规则变化时递增，旧索引会被整体重建
+const bm25Version = 1
+
+// 文本中的英文标识符，如 MailStatusFailed、order_details.fee
+var identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*`)
+
+// BM25Index 分块的关键词倒排索引，用于精确匹配标识符等嵌入向量容易漏掉的查询
+type BM25Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*bm25Doc
+	postings map[string]map[string]int // 词 -> 分块 ID -> 词频
+	totalLen int
+}
+
+type bm25Doc struct {
+	ID       string
+	Content  string
+	Metadata map[string]interface{}
+	Terms    map[string]int
+	Length   int
+}
+
+// 持久化文件内容，倒排表在加载时由分块的词频重建
+type bm25File struct {
+	Version int
+	Docs    []*bm25Doc
+}
+
+// NewBM25Index 创建空索引
+func NewBM25Index() *BM25Index {
+	return &BM25Index{
+		docs:     make(map[string]*bm25Doc),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// LoadBM25Index 从文件加载索引，文件不存在或版本不一致时返回空索引
+func LoadBM25Index(path string) (*BM25Index, error) {
+	index := NewBM25Index()
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开关键词索引失败: %w", err)
+	}
+	defer f.Close()
+
+	var data bm25File
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("读取关键词索引 %s 失败: %w", path, err)
+	}
+	if data.Version != bm25Version {
+		return index, nil
+	}
+	for _, doc := range data.Docs {
+		index.addDoc(doc)
+	}
+	return index, nil
+}
+
+// Save 写入文件，先写临时文件再重命名
+func (idx *BM25Index) Save(path string) error {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	data := bm25File{Version: bm25Version, Docs: make([]*bm25Doc, 0, len(idx.docs))}
+	for _, doc := range idx.docs {
+		data.Docs = append(data.Docs, doc)
+	}
+	if err := writeFileAtomic(path, func(w io.Writer) error {
+		return gob.NewEncoder(w).Encode(&data)
+	}); err != nil {
+		return fmt.Errorf("写入关键词索引失败: %w", err)
+	}
+	return nil
+}
+
+// 关键词索引文件路径，每个集合一个
+func bm25IndexPath(persistDir, collection string) string {
+	return filepath.Join(persistDir, "bm25_"+collection+".gob")
+}
+
+// Len 返回分块数
+func (idx *BM25Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// 判断分块是否都已索引
+func (idx *BM25Index) hasAll(ids []string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	for _, id := range ids {
+		if _, ok := idx.docs[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Add 索引分块，ID 已存在时覆盖
+func (idx *BM25Index) Add(id, content string, metadata map[string]interface{}) {
+	terms := make(map[string]int)
+	tokens := Tokenize(content)
+	for _, token := range tokens {
+		terms[token]++
+	}
+	copied := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeDoc(id)
+	idx.addDoc(&bm25Doc{ID: id, Content: content, Metadata: copied, Terms: terms, Length: len(tokens)})
+}
+
+// Reset 清空索引
+func (idx *BM25Index) Reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.docs = make(map[string]*bm25Doc)
+	idx.postings = make(map[string]map[string]int)
+	idx.totalLen = 0
+}
+
+// Delete 删除满足过滤条件的分块，条件为空时不删除
+func (idx *BM25Index) Delete(filter Filter) int {
+	if filter.Empty() {
+		return 0
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	removed := 0
+	for id, doc := range idx.docs {
+		if filter.matches(id, doc.Metadata) {
+			idx.removeDoc(id)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (idx *BM25Index) addDoc(doc *bm25Doc) {
+	idx.docs[doc.ID] = doc
+	idx.totalLen += doc.Length
+	for term, tf := range doc.Terms {
+		posting := idx.postings[term]
+		if posting == nil {
+			posting = make(map[string]int)
+			idx.postings[term] = posting
+		}
+		posting[doc.ID] = tf
+	}
+}
+
+func (idx *BM25Index) removeDoc(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	delete(idx.docs, id)
+	idx.totalLen -= doc.Length
+	for term := range doc.Terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+}
+
+// Search 返回与查询 BM25 得分最高且满足过滤条件的 k 个分块
+func (idx *BM25Index) Search(query string, k int, filter Filter) []ScoredPoint {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if len(idx.docs) == 0 || k <= 0 {
+		return nil
+	}
+
+	n := float64(len(idx.docs))
+	avgLen := float64(idx.totalLen) / n
+	scores := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		posting := idx.postings[term]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range posting {
+			length := float64(idx.docs[id].Length)
+			f := float64(tf)
+			scores[id] += idf * f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*length/avgLen))
+		}
+	}
+
+	results := make([]ScoredPoint, 0, len(scores))
+	for id, score := range scores {
+		doc := idx.docs[id]
+		if !filter.matches(id, doc.Metadata) {
+			continue
+		}
+		metadata := make(map[string]interface{}, len(doc.Metadata))
+		for key, value := range doc.Metadata {
+			metadata[key] = value
+		}
+		results = append(results, ScoredPoint{
+			Point: Point{ID: id, Content: doc.Content, Metadata: metadata},
+			Score: float32(score),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// Tokenize 把文本切分为检索用的词：中文按词典切分，英文标识符按驼峰和下划线拆分，
+// 同时保留完整的标识符，如 MailStatusFailed 得到 mailstatusfailed、mail、status、failed，
+// order_details.fee 得到 order_details.fee、order_details、order、details、fee。停用词被丢弃
+func Tokenize(text string) []string {
+	seg := segment.Default()
+	var tokens []string
+	for _, word := range seg.Cut(text) {
+		if !seg.IsStopword(word) {
+			tokens = append(tokens, word)
+		}
+	}
+	for _, ident := range identifierPattern.FindAllString(text, -1) {
+		parts := strings.Split(ident, ".")
+		if len(parts) > 1 {
+			tokens = append(tokens, strings.ToLower(ident))
+		}
+		for _, part := range parts {
+			if len(seg.Cut(part)) > 1 {
+				tokens = append(tokens, strings.ToLower(part))
+			}
+		}
+	}
+	return tokens
+}
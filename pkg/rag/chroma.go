@@ -0,0 +1,334 @@
+package rag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Chroma 的默认租户和数据库
+const (
+	chromaTenant   = "default_tenant"
+	chromaDatabase = "default_database"
+)
+
+// ChromaBackend 通过 REST 接口（/api/v2）访问 Chroma 集合，支持 Chroma 1.0 及以上版本，
+// 集合建在默认租户和数据库下。
+// Chroma 的元数据只支持字符串、数字和布尔值，其他类型的值按 JSON 字符串保存
+type ChromaBackend struct {
+	url        string
+	collection string
+	client     *http.Client
+
+	mu sync.Mutex
+	id string // 集合 ID，首次使用时查询
+}
+
+var _ Backend = (*ChromaBackend)(nil)
+
+// NewChromaBackend 创建 Chroma 后端，url 为服务地址，如 http://localhost:8000
+func NewChromaBackend(url, collection string) *ChromaBackend {
+	return &ChromaBackend{
+		url:        strings.TrimRight(url, "/"),
+		collection: collection,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateCollection 创建使用余弦距离的集合，已存在时直接使用
+func (b *ChromaBackend) CreateCollection(ctx context.Context, _ int) error {
+	body := map[string]interface{}{
+		"name":          b.collection,
+		"metadata":      map[string]interface{}{"hnsw:space": "cosine"},
+		"get_or_create": true,
+	}
+	var result struct {
+		ID string `json:"id"`
+	}
+	if _, err := b.request(ctx, http.MethodPost, b.collectionsPath(), body, &result); err != nil {
+		return fmt.Errorf("创建集合失败: %w", err)
+	}
+	b.mu.Lock()
+	b.id = result.ID
+	b.mu.Unlock()
+	return nil
+}
+
+// Upsert 写入点
+func (b *ChromaBackend) Upsert(ctx context.Context, points []Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	id, err := b.collectionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(points))
+	embeddings := make([][]float32, len(points))
+	documents := make([]string, len(points))
+	metadatas := make([]map[string]interface{}, len(points))
+	for i, p := range points {
+		ids[i] = p.ID
+		embeddings[i] = p.Vector
+		documents[i] = p.Content
+		metadatas[i] = chromaMetadata(p.Metadata)
+	}
+
+	body := map[string]interface{}{
+		"ids":        ids,
+		"embeddings": embeddings,
+		"documents":  documents,
+		"metadatas":  metadatas,
+	}
+	if _, err := b.request(ctx, http.MethodPost, b.collectionsPath()+"/"+id+"/upsert", body, nil); err != nil {
+		return fmt.Errorf("写入向量失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除满足过滤条件的点。Chroma 的 where 条件不能排除 ID，
+// 有 ExcludeIDs 时先查出匹配的 ID 再按 ID 删除
+func (b *ChromaBackend) Delete(ctx context.Context, filter Filter) error {
+	if filter.Empty() {
+		return nil
+	}
+	id, err := b.collectionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{}
+	if len(filter.ExcludeIDs) == 0 {
+		body["where"] = chromaWhere(filter.Match)
+	} else {
+		query := map[string]interface{}{"include": []string{}}
+		if len(filter.Match) > 0 {
+			query["where"] = chromaWhere(filter.Match)
+		}
+		var matched struct {
+			IDs []string `json:"ids"`
+		}
+		if _, err := b.request(ctx, http.MethodPost, b.collectionsPath()+"/"+id+"/get", query, &matched); err != nil {
+			return fmt.Errorf("查询待删除的向量失败: %w", err)
+		}
+		excluded := make(map[string]bool, len(filter.ExcludeIDs))
+		for _, id := range filter.ExcludeIDs {
+			excluded[id] = true
+		}
+		var ids []string
+		for _, id := range matched.IDs {
+			if !excluded[id] {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		body["ids"] = ids
+	}
+
+	if _, err := b.request(ctx, http.MethodPost, b.collectionsPath()+"/"+id+"/delete", body, nil); err != nil {
+		return fmt.Errorf("删除向量失败: %w", err)
+	}
+	return nil
+}
+
+// Search 检索最相近的 k 个点，余弦距离转换为余弦相似度
+func (b *ChromaBackend) Search(ctx context.Context, vector []float32, k int, filter Filter) ([]ScoredPoint, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	id, err := b.collectionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 排除的 ID 只能在结果中过滤，多取一些保证数量
+	body := map[string]interface{}{
+		"query_embeddings": [][]float32{vector},
+		"n_results":        k + len(filter.ExcludeIDs),
+		"include":          []string{"documents", "metadatas", "distances"},
+	}
+	if len(filter.Match) > 0 {
+		body["where"] = chromaWhere(filter.Match)
+	}
+
+	var result struct {
+		IDs       [][]string                 `json:"ids"`
+		Documents [][]string                 `json:"documents"`
+		Metadatas [][]map[string]interface{} `json:"metadatas"`
+		Distances [][]float32                `json:"distances"`
+	}
+	if _, err := b.request(ctx, http.MethodPost, b.collectionsPath()+"/"+id+"/query", body, &result); err != nil {
+		return nil, fmt.Errorf("检索向量失败: %w", err)
+	}
+	if len(result.IDs) == 0 {
+		return nil, nil
+	}
+
+	excluded := Filter{ExcludeIDs: filter.ExcludeIDs}
+	var results []ScoredPoint
+	for i, pointID := range result.IDs[0] {
+		if !excluded.matches(pointID, nil) {
+			continue
+		}
+		p := ScoredPoint{Point: Point{ID: pointID}}
+		if len(result.Documents) > 0 && i < len(result.Documents[0]) {
+			p.Content = result.Documents[0][i]
+		}
+		if len(result.Metadatas) > 0 && i < len(result.Metadatas[0]) {
+			p.Metadata = result.Metadatas[0][i]
+		}
+		if len(result.Distances) > 0 && i < len(result.Distances[0]) {
+			p.Score = 1 - result.Distances[0][i]
+		}
+		results = append(results, p)
+		if len(results) == k {
+			break
+		}
+	}
+	return results, nil
+}
+
+// Count 返回集合中点的数量
+func (b *ChromaBackend) Count(ctx context.Context) (int, error) {
+	id, err := b.collectionID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if _, err := b.request(ctx, http.MethodGet, b.collectionsPath()+"/"+id+"/count", nil, &count); err != nil {
+		return 0, fmt.Errorf("统计向量数量失败: %w", err)
+	}
+	return count, nil
+}
+
+// Drop 删除集合
+func (b *ChromaBackend) Drop(ctx context.Context) error {
+	status, err := b.request(ctx, http.MethodDelete, b.collectionsPath()+"/"+url.PathEscape(b.collection), nil, nil)
+	// 集合不存在时 Chroma 返回 404，部分版本返回 400
+	if err != nil && status != http.StatusNotFound && status != http.StatusBadRequest {
+		return fmt.Errorf("删除集合失败: %w", err)
+	}
+	b.mu.Lock()
+	b.id = ""
+	b.mu.Unlock()
+	return nil
+}
+
+// Flush Chroma 的写入在请求返回时已完成
+func (b *ChromaBackend) Flush(context.Context) error { return nil }
+
+// Close 没有需要释放的资源
+func (b *ChromaBackend) Close() error { return nil }
+
+// 默认租户和数据库下的集合接口路径
+func (b *ChromaBackend) collectionsPath() string {
+	return "/api/v2/tenants/" + chromaTenant + "/databases/" + chromaDatabase + "/collections"
+}
+
+// 返回集合 ID，尚未获取时按名称查询
+func (b *ChromaBackend) collectionID(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.id != "" {
+		return b.id, nil
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if _, err := b.request(ctx, http.MethodGet, b.collectionsPath()+"/"+url.PathEscape(b.collection), nil, &result); err != nil {
+		return "", fmt.Errorf("查询集合 %s 失败: %w", b.collection, err)
+	}
+	b.id = result.ID
+	return b.id, nil
+}
+
+// 调用 Chroma REST 接口，把响应解析到 out，返回 HTTP 状态码
+func (b *ChromaBackend) request(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+		reader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.url+path, reader)
+	if err != nil {
+		return 0, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("发送HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, fmt.Errorf("状态码: %d，响应: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("解析响应体失败: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// 转换为 Chroma 支持的元数据，空元数据返回 nil
+func chromaMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if len(metadata) == 0 {
+		return nil
+	}
+	result := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		switch value.(type) {
+		case string, bool, int, int32, int64, float32, float64:
+			result[key] = value
+		default:
+			data, err := json.Marshal(value)
+			if err != nil {
+				data = []byte(fmt.Sprint(value))
+			}
+			result[key] = string(data)
+		}
+	}
+	return result
+}
+
+// 转换为 Chroma 的 where 条件，多个键用 $and 组合
+func chromaWhere(match map[string]interface{}) map[string]interface{} {
+	keys := make([]string, 0, len(match))
+	for key := range match {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var conditions []interface{}
+	for _, key := range keys {
+		value := match[key]
+		if values, ok := value.([]interface{}); ok {
+			value = map[string]interface{}{"$in": values}
+		}
+		conditions = append(conditions, map[string]interface{}{key: value})
+	}
+	if len(conditions) == 1 {
+		return conditions[0].(map[string]interface{})
+	}
+	return map[string]interface{}{"$and": conditions}
+}
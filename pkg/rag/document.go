@@ -41,11 +41,12 @@ func (dl *DocumentLoader) LoadDocuments(ctx context.Context) ([]schema.Document,
 				return fmt.Errorf("读取文件 %s 失败: %w", path, err)
 			}
 
-			// 创建文档
+			// 创建文档，章节级的元数据在分割时附加
 			doc := schema.Document{
 				PageContent: string(content),
 				Metadata: map[string]any{
-					"source": path,
+					MetaSource:   path,
+					MetaModified: info.ModTime().Unix(),
 				},
 			}
 
@@ -61,7 +62,9 @@ func (dl *DocumentLoader) LoadDocuments(ctx context.Context) ([]schema.Document,
 	return allDocs, nil
 }
 
-// SplitDocuments 将文档分割成更小的块
+// SplitDocuments 将文档分割成更小的块。docgen 生成的文档先按元数据注释切分为章节，
+// 每个枚举、表等单独成块，并带上章节的项目、文档类型、包等元数据；过长的章节再按长度分割。
+// 每个分块记录所在的标题路径，用于回答中的引用
 func (dl *DocumentLoader) SplitDocuments(docs []schema.Document) ([]schema.Document, error) {
 	// 创建文本分割器
 	splitter := textsplitter.NewTokenSplitter(
@@ -71,21 +74,90 @@ func (dl *DocumentLoader) SplitDocuments(docs []schema.Document) ([]schema.Docum
 
 	var splitDocs []schema.Document
 	for _, doc := range docs {
-		// 分割文本内容
-		texts, err := splitter.SplitText(doc.PageContent)
-		if err != nil {
-			return nil, fmt.Errorf("分割文本失败: %w", err)
-		}
+		var trail headingTrail
+		for _, section := range splitSections(doc.PageContent) {
+			// 分割文本内容
+			texts, err := splitter.SplitText(section.Text)
+			if err != nil {
+				return nil, fmt.Errorf("分割文本失败: %w", err)
+			}
 
-		// 为每个分割后的文本创建新文档
-		for _, text := range texts {
-			splitDoc := schema.Document{
-				PageContent: text,
-				Metadata:    doc.Metadata,
+			// 为每个分割后的文本创建新文档
+			for _, text := range texts {
+				metadata := make(map[string]any, len(doc.Metadata)+len(section.Metadata)+1)
+				for key, value := range doc.Metadata {
+					metadata[key] = value
+				}
+				for key, value := range section.Metadata {
+					metadata[key] = value
+				}
+				if headings := trail.advance(text); headings != "" {
+					metadata[MetaHeadings] = headings
+				}
+				splitDocs = append(splitDocs, schema.Document{
+					PageContent: text,
+					Metadata:    metadata,
+				})
 			}
-			splitDocs = append(splitDocs, splitDoc)
 		}
 	}
 
 	return splitDocs, nil
 }
+
+// 文档中当前所在的各级标题，下标为标题级别减一
+type headingTrail []string
+
+// advance 返回分块开头所在的标题路径，如“数据库表 > order_details（订单详情表）”，
+// 并按分块中的标题更新当前位置。分块开头的标题计入该分块的路径，代码块中的 # 行不是标题
+func (t *headingTrail) advance(chunk string) string {
+	path := ""
+	leading := true
+	inFence := false
+	for _, line := range strings.Split(chunk, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+		}
+		level, title := 0, ""
+		if !inFence {
+			level, title = parseHeading(line)
+		}
+		if level > 0 {
+			for len(*t) < level {
+				*t = append(*t, "")
+			}
+			*t = append((*t)[:level-1], title)
+		} else if line != "" && leading {
+			leading = false
+			path = t.String()
+		}
+	}
+	if leading {
+		path = t.String()
+	}
+	return path
+}
+
+// String 返回用 > 连接的标题路径
+func (t headingTrail) String() string {
+	var titles []string
+	for _, title := range t {
+		if title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return strings.Join(titles, " > ")
+}
+
+// 解析 Markdown 标题行，返回级别和标题文字，不是标题时级别为 0
+func parseHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(line[level:])
+}
@@ -0,0 +1,451 @@
+package rag
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// HNSW 索引文件的格式版本
+const hnswVersion = 1
+
+// HNSWConfig HNSW 索引参数
+type HNSWConfig struct {
+	M              int `yaml:"m"`               // 每个节点在上层的最大连接数，第 0 层为 2M
+	EfConstruction int `yaml:"ef_construction"` // 构建时的候选集大小，越大图质量越好、插入越慢
+	EfSearch       int `yaml:"ef_search"`       // 查询时的候选集大小，越大召回率越高、查询越慢
+}
+
+// DefaultHNSWConfig 返回默认的 HNSW 参数
+func DefaultHNSWConfig() HNSWConfig {
+	return HNSWConfig{M: 16, EfConstruction: 200, EfSearch: 64}
+}
+
+// 未设置的参数使用默认值
+func (c HNSWConfig) withDefaults() HNSWConfig {
+	def := DefaultHNSWConfig()
+	if c.M <= 1 {
+		c.M = def.M
+	}
+	if c.EfConstruction <= 0 {
+		c.EfConstruction = def.EfConstruction
+	}
+	if c.EfSearch <= 0 {
+		c.EfSearch = def.EfSearch
+	}
+	return c
+}
+
+// HNSWResult 一条近邻查询结果，Score 为余弦相似度
+type HNSWResult struct {
+	ID    string
+	Score float32
+}
+
+type hnswNode struct {
+	id      string
+	vector  []float32 // 已归一化
+	level   int
+	links   [][]int32 // 每层的邻居
+	deleted bool
+}
+
+// HNSW 分层可导航小世界图，用于近似最近邻检索。向量需要事先归一化，距离为 1 - 余弦相似度。
+// 删除只做标记，被删除的节点仍参与图的遍历但不会出现在结果中，删除过多时通过 Rebuild 重建。
+// 非并发安全，由调用方加锁
+type HNSW struct {
+	config   HNSWConfig
+	levelMul float64
+	nodes    []*hnswNode
+	ids      map[string]int32 // 未删除节点的 ID 到下标
+	entry    int32
+	maxLevel int
+	deleted  int
+	rng      *rand.Rand
+}
+
+// NewHNSW 创建空索引
+func NewHNSW(config HNSWConfig) *HNSW {
+	config = config.withDefaults()
+	return &HNSW{
+		config:   config,
+		levelMul: 1 / math.Log(float64(config.M)),
+		ids:      make(map[string]int32),
+		entry:    -1,
+		rng:      rand.New(rand.NewSource(1)),
+	}
+}
+
+// Config 返回索引参数
+func (h *HNSW) Config() HNSWConfig {
+	return h.config
+}
+
+// Len 返回未删除的节点数
+func (h *HNSW) Len() int {
+	return len(h.ids)
+}
+
+// SetEfSearch 调整查询时的候选集大小，不影响已构建的图
+func (h *HNSW) SetEfSearch(ef int) {
+	if ef > 0 {
+		h.config.EfSearch = ef
+	}
+}
+
+// Add 插入向量，ID 已存在时先删除旧节点
+func (h *HNSW) Add(id string, vector []float32) {
+	if old, ok := h.ids[id]; ok {
+		h.markDeleted(old)
+	}
+
+	// 层数服从指数分布，1-Float64 的取值范围为 (0, 1]，避免 log(0)
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMul))
+	node := &hnswNode{id: id, vector: vector, level: level, links: make([][]int32, level+1)}
+	idx := int32(len(h.nodes))
+	h.nodes = append(h.nodes, node)
+	h.ids[id] = idx
+
+	if h.entry < 0 {
+		h.entry = idx
+		h.maxLevel = level
+		return
+	}
+
+	// 在高于新节点的层上贪心下降，找到最近的入口
+	ep := h.entry
+	epDist := h.distance(vector, h.nodes[ep].vector)
+	for l := h.maxLevel; l > level; l-- {
+		ep, epDist = h.greedy(vector, ep, epDist, l)
+	}
+
+	entryPoints := []hnswCandidate{{id: ep, dist: epDist}}
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(vector, entryPoints, h.config.EfConstruction, l)
+		neighbors := h.selectNeighbors(candidates, h.config.M)
+		node.links[l] = make([]int32, 0, len(neighbors))
+		for _, n := range neighbors {
+			node.links[l] = append(node.links[l], n.id)
+			h.connect(n.id, idx, l)
+		}
+		entryPoints = candidates
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entry = idx
+	}
+}
+
+// Delete 标记删除，返回 ID 是否存在
+func (h *HNSW) Delete(id string) bool {
+	idx, ok := h.ids[id]
+	if ok {
+		h.markDeleted(idx)
+	}
+	return ok
+}
+
+func (h *HNSW) markDeleted(idx int32) {
+	node := h.nodes[idx]
+	node.deleted = true
+	delete(h.ids, node.id)
+	h.deleted++
+}
+
+// NeedsRebuild 被删除的节点超过一半时建议重建，避免图中大量无效节点拖慢查询
+func (h *HNSW) NeedsRebuild() bool {
+	return h.deleted > 0 && h.deleted*2 > len(h.nodes)
+}
+
+// Rebuild 按原插入顺序用未删除的节点重建索引
+func (h *HNSW) Rebuild() {
+	fresh := NewHNSW(h.config)
+	for _, node := range h.nodes {
+		if !node.deleted {
+			fresh.Add(node.id, node.vector)
+		}
+	}
+	*h = *fresh
+}
+
+// Search 返回与 vector 最相近的 k 个节点，ef 不大于 0 时使用配置的 EfSearch
+func (h *HNSW) Search(vector []float32, k, ef int) []HNSWResult {
+	if h.entry < 0 || k <= 0 {
+		return nil
+	}
+	if ef <= 0 {
+		ef = h.config.EfSearch
+	}
+
+	ep := h.entry
+	epDist := h.distance(vector, h.nodes[ep].vector)
+	for l := h.maxLevel; l > 0; l-- {
+		ep, epDist = h.greedy(vector, ep, epDist, l)
+	}
+	candidates := h.searchLayer(vector, []hnswCandidate{{id: ep, dist: epDist}}, max(ef, k), 0)
+
+	results := make([]HNSWResult, 0, k)
+	for _, c := range candidates {
+		node := h.nodes[c.id]
+		if node.deleted {
+			continue
+		}
+		results = append(results, HNSWResult{ID: node.id, Score: 1 - c.dist})
+		if len(results) == k {
+			break
+		}
+	}
+	return results
+}
+
+func (h *HNSW) distance(a, b []float32) float32 {
+	return 1 - dot(a, b)
+}
+
+func (h *HNSW) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * h.config.M
+	}
+	return h.config.M
+}
+
+// 在单层上贪心移动到更近的邻居，直到无法更近
+func (h *HNSW) greedy(vector []float32, ep int32, epDist float32, level int) (int32, float32) {
+	for changed := true; changed; {
+		changed = false
+		for _, n := range h.nodes[ep].links[level] {
+			if d := h.distance(vector, h.nodes[n].vector); d < epDist {
+				ep, epDist = n, d
+				changed = true
+			}
+		}
+	}
+	return ep, epDist
+}
+
+// 在单层上做束搜索，返回按距离从近到远排列的最多 ef 个候选
+func (h *HNSW) searchLayer(vector []float32, entryPoints []hnswCandidate, ef, level int) []hnswCandidate {
+	visited := make(map[int32]bool, ef*4)
+	candidates := &candidateHeap{}            // 待扩展，距离小的在堆顶
+	results := &candidateHeap{farthest: true} // 当前结果，距离大的在堆顶
+	for _, ep := range entryPoints {
+		if visited[ep.id] {
+			continue
+		}
+		visited[ep.id] = true
+		heap.Push(candidates, ep)
+		heap.Push(results, ep)
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && c.dist > results.items[0].dist {
+			break
+		}
+		node := h.nodes[c.id]
+		if level >= len(node.links) {
+			continue
+		}
+		for _, n := range node.links[level] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+			d := h.distance(vector, h.nodes[n].vector)
+			if results.Len() < ef || d < results.items[0].dist {
+				heap.Push(candidates, hnswCandidate{id: n, dist: d})
+				heap.Push(results, hnswCandidate{id: n, dist: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	sorted := make([]hnswCandidate, results.Len())
+	for i := len(sorted) - 1; i >= 0; i-- {
+		sorted[i] = heap.Pop(results).(hnswCandidate)
+	}
+	return sorted
+}
+
+// 启发式选择邻居：优先保留彼此分散的候选，使图在簇之间也保持连通；
+// 不足 m 个时用被跳过的候选补齐。candidates 需按距离从近到远排列
+func (h *HNSW) selectNeighbors(candidates []hnswCandidate, m int) []hnswCandidate {
+	if len(candidates) <= m {
+		return candidates
+	}
+	selected := make([]hnswCandidate, 0, m)
+	var skipped []hnswCandidate
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		diverse := true
+		for _, s := range selected {
+			if h.distance(h.nodes[c.id].vector, h.nodes[s.id].vector) < c.dist {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c)
+		} else {
+			skipped = append(skipped, c)
+		}
+	}
+	for _, c := range skipped {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, c)
+	}
+	return selected
+}
+
+// 为节点 from 添加指向 to 的反向连接，超出上限时重新挑选邻居
+func (h *HNSW) connect(from, to int32, level int) {
+	node := h.nodes[from]
+	node.links[level] = append(node.links[level], to)
+	limit := h.maxLinks(level)
+	if len(node.links[level]) <= limit {
+		return
+	}
+
+	candidates := make([]hnswCandidate, 0, len(node.links[level]))
+	for _, n := range node.links[level] {
+		candidates = append(candidates, hnswCandidate{id: n, dist: h.distance(node.vector, h.nodes[n].vector)})
+	}
+	sortCandidates(candidates)
+	selected := h.selectNeighbors(candidates, limit)
+	node.links[level] = node.links[level][:0]
+	for _, c := range selected {
+		node.links[level] = append(node.links[level], c.id)
+	}
+}
+
+// 索引文件内容：只保存图结构，未删除节点的向量从负载文件中取回
+type hnswFile struct {
+	Version    int
+	Generation uint64 // 与负载文件一致时索引才可用
+	Config     HNSWConfig
+	Entry      int32
+	MaxLevel   int
+	Nodes      []hnswFileNode
+}
+
+type hnswFileNode struct {
+	ID      string
+	Level   int
+	Links   [][]int32
+	Deleted bool
+	Vector  []float32 // 只有已删除的节点保存向量
+}
+
+// Encode 把索引写入 w
+func (h *HNSW) Encode(w io.Writer, generation uint64) error {
+	data := hnswFile{
+		Version:    hnswVersion,
+		Generation: generation,
+		Config:     h.config,
+		Entry:      h.entry,
+		MaxLevel:   h.maxLevel,
+		Nodes:      make([]hnswFileNode, len(h.nodes)),
+	}
+	for i, node := range h.nodes {
+		data.Nodes[i] = hnswFileNode{ID: node.id, Level: node.level, Links: node.links, Deleted: node.deleted}
+		if node.deleted {
+			data.Nodes[i].Vector = node.vector
+		}
+	}
+	return gob.NewEncoder(w).Encode(&data)
+}
+
+// DecodeHNSW 从 r 读取索引，vectors 按 ID 返回未删除节点的向量。
+// 返回索引对应的负载文件版本
+func DecodeHNSW(r io.Reader, vectors func(id string) ([]float32, bool)) (*HNSW, uint64, error) {
+	var data hnswFile
+	if err := gob.NewDecoder(r).Decode(&data); err != nil {
+		return nil, 0, fmt.Errorf("读取 HNSW 索引失败: %w", err)
+	}
+	if data.Version != hnswVersion {
+		return nil, 0, fmt.Errorf("不支持的 HNSW 索引版本: %d", data.Version)
+	}
+
+	h := NewHNSW(data.Config)
+	h.entry = data.Entry
+	h.maxLevel = data.MaxLevel
+	h.nodes = make([]*hnswNode, len(data.Nodes))
+	for i, n := range data.Nodes {
+		node := &hnswNode{id: n.ID, level: n.Level, links: n.Links, deleted: n.Deleted, vector: n.Vector}
+		if n.Deleted {
+			h.deleted++
+		} else {
+			vector, ok := vectors(n.ID)
+			if !ok {
+				return nil, 0, fmt.Errorf("HNSW 索引中的 %s 在负载中不存在", n.ID)
+			}
+			node.vector = vector
+			h.ids[n.ID] = int32(i)
+		}
+		if node.level < 0 || len(node.links) < node.level+1 {
+			return nil, 0, fmt.Errorf("HNSW 索引中的 %s 层数不完整", n.ID)
+		}
+		for _, links := range node.links {
+			for _, link := range links {
+				if link < 0 || int(link) >= len(data.Nodes) {
+					return nil, 0, fmt.Errorf("HNSW 索引中的 %s 连接了不存在的节点 %d", n.ID, link)
+				}
+			}
+		}
+		h.nodes[i] = node
+	}
+
+	// 入口节点必须存在，且层数覆盖最高层，否则检索时会越界
+	if len(h.nodes) == 0 {
+		if h.entry != -1 {
+			return nil, 0, fmt.Errorf("空的 HNSW 索引入口节点为 %d", h.entry)
+		}
+	} else if h.entry < 0 || int(h.entry) >= len(h.nodes) || h.maxLevel < 0 || h.maxLevel > h.nodes[h.entry].level {
+		return nil, 0, fmt.Errorf("HNSW 索引的入口节点 %d 或最高层 %d 无效", h.entry, h.maxLevel)
+	}
+	return h, data.Generation, nil
+}
+
+type hnswCandidate struct {
+	id   int32
+	dist float32
+}
+
+// candidateHeap 按距离排序的堆，farthest 为 true 时距离最大的在堆顶
+type candidateHeap struct {
+	items    []hnswCandidate
+	farthest bool
+}
+
+func (h *candidateHeap) Len() int { return len(h.items) }
+func (h *candidateHeap) Less(i, j int) bool {
+	if h.farthest {
+		return h.items[i].dist > h.items[j].dist
+	}
+	return h.items[i].dist < h.items[j].dist
+}
+func (h *candidateHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *candidateHeap) Push(x interface{}) { h.items = append(h.items, x.(hnswCandidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	x := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return x
+}
+
+func sortCandidates(c []hnswCandidate) {
+	sort.Slice(c, func(i, j int) bool { return c[i].dist < c[j].dist })
+}
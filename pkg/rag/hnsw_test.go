@@ -0,0 +1,132 @@
+package rag
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// 生成 n 个归一化的随机向量
+func randomVectors(rng *rand.Rand, n, dim int) [][]float32 {
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		v := make([]float32, dim)
+		for j := range v {
+			v[j] = float32(rng.NormFloat64())
+		}
+		vectors[i] = normalize(v)
+	}
+	return vectors
+}
+
+func buildHNSW(vectors [][]float32) *HNSW {
+	h := NewHNSW(DefaultHNSWConfig())
+	for i, v := range vectors {
+		h.Add(fmt.Sprintf("p%d", i), v)
+	}
+	return h
+}
+
+// 精确检索的前 k 个 ID
+func bruteForce(vectors [][]float32, query []float32, k int) []string {
+	type scored struct {
+		id    string
+		score float32
+	}
+	all := make([]scored, len(vectors))
+	for i, v := range vectors {
+		all[i] = scored{id: fmt.Sprintf("p%d", i), score: dot(query, v)}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].score > all[j].score })
+	ids := make([]string, k)
+	for i := range ids {
+		ids[i] = all[i].id
+	}
+	return ids
+}
+
+func TestHNSWRecall(t *testing.T) {
+	const n, dim, k, queries = 2000, 32, 10, 50
+	rng := rand.New(rand.NewSource(42))
+	vectors := randomVectors(rng, n, dim)
+	h := buildHNSW(vectors)
+
+	hits := 0
+	for _, query := range randomVectors(rng, queries, dim) {
+		want := make(map[string]bool, k)
+		for _, id := range bruteForce(vectors, query, k) {
+			want[id] = true
+		}
+		for _, r := range h.Search(query, k, 0) {
+			if want[r.ID] {
+				hits++
+			}
+		}
+	}
+	recall := float64(hits) / float64(queries*k)
+	t.Logf("recall@%d = %.3f", k, recall)
+	if recall < 0.9 {
+		t.Fatalf("召回率 %.3f 低于 0.9", recall)
+	}
+}
+
+func TestDecodeHNSWRejectsInvalidLinks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	vectors := randomVectors(rng, 50, 8)
+	h := buildHNSW(vectors)
+	lookup := func(id string) ([]float32, bool) {
+		var i int
+		if _, err := fmt.Sscanf(id, "p%d", &i); err != nil || i >= len(vectors) {
+			return nil, false
+		}
+		return vectors[i], true
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func(h *HNSW)
+	}{
+		{"入口越界", func(h *HNSW) { h.entry = int32(len(h.nodes)) }},
+		{"连接越界", func(h *HNSW) { h.nodes[0].links[0] = append(h.nodes[0].links[0], int32(len(h.nodes)+5)) }},
+		{"负数连接", func(h *HNSW) { h.nodes[1].links[0] = append(h.nodes[1].links[0], -1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrupted := buildHNSW(vectors)
+			tt.corrupt(corrupted)
+			var buf bytes.Buffer
+			if err := corrupted.Encode(&buf, 1); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := DecodeHNSW(&buf, lookup); err == nil {
+				t.Fatal("损坏的索引应当返回错误")
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := h.Encode(&buf, 7); err != nil {
+		t.Fatal(err)
+	}
+	decoded, generation, err := DecodeHNSW(&buf, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generation != 7 || decoded.Len() != len(vectors) {
+		t.Fatalf("解码结果不一致: generation=%d len=%d", generation, decoded.Len())
+	}
+}
+
+func BenchmarkHNSWSearch(b *testing.B) {
+	rng := rand.New(rand.NewSource(42))
+	vectors := randomVectors(rng, 5000, 64)
+	h := buildHNSW(vectors)
+	queries := randomVectors(rng, 100, 64)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Search(queries[i%len(queries)], 10, 0)
+	}
+}
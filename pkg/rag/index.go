@@ -0,0 +1,137 @@
+package rag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// 索引清单的格式版本，分块或 ID 规则变化时递增，旧清单会被整体重建
+const indexManifestVersion = 4
+
+// 生成点 ID 的命名空间，ID 为 UUIDv5，同一分块在不同运行间保持不变
+var chunkIDNamespace = uuid.MustParse("6f1c2b9e-3d4a-5b8c-9e0f-1a2b3c4d5e6f")
+
+// IndexManifest 记录已写入向量存储的文件和分块，用于增量索引
+type IndexManifest struct {
+	Version        int                    `json:"version"`
+	Collection     string                 `json:"collection"`
+	EmbeddingModel string                 `json:"embedding_model"`
+	Files          map[string]IndexedFile `json:"files"` // 按文档来源路径索引
+}
+
+// IndexedFile 已索引文件的内容摘要和分块 ID
+type IndexedFile struct {
+	Hash   string   `json:"hash"`
+	Chunks []string `json:"chunks"`
+}
+
+// IndexStats 一次索引的变化统计，文件数按新增、更新、删除和未变化分类
+type IndexStats struct {
+	Added     int // 新增的文件
+	Updated   int // 内容变化的文件
+	Deleted   int // 已删除的文件
+	Unchanged int // 未变化的文件
+	Upserted  int // 写入的分块
+	Removed   int // 删除的分块
+}
+
+// String 返回统计摘要
+func (s IndexStats) String() string {
+	return fmt.Sprintf("文件新增 %d、更新 %d、删除 %d、未变化 %d，写入 %d 个分块，删除 %d 个分块",
+		s.Added, s.Updated, s.Deleted, s.Unchanged, s.Upserted, s.Removed)
+}
+
+// 清单文件路径，每个集合一个
+func indexManifestPath(persistDir, collection string) string {
+	return filepath.Join(persistDir, "index_"+collection+".json")
+}
+
+// 分块总数
+func (m *IndexManifest) chunkCount() int {
+	n := 0
+	for _, file := range m.Files {
+		n += len(file.Chunks)
+	}
+	return n
+}
+
+func newIndexManifest(collection, embeddingModel string) *IndexManifest {
+	return &IndexManifest{
+		Version:        indexManifestVersion,
+		Collection:     collection,
+		EmbeddingModel: embeddingModel,
+		Files:          make(map[string]IndexedFile),
+	}
+}
+
+// 加载索引清单，文件不存在、已损坏或与当前集合、嵌入模型不一致时返回空清单，
+// 此时所有文件都会重新写入
+func loadIndexManifest(path, collection, embeddingModel string) (*IndexManifest, error) {
+	empty := newIndexManifest(collection, embeddingModel)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取索引清单失败: %w", err)
+	}
+
+	var manifest IndexManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		log.Printf("索引清单 %s 已损坏，重新建立索引: %v", path, err)
+		return empty, nil
+	}
+	if manifest.Version != indexManifestVersion || manifest.Collection != collection ||
+		manifest.EmbeddingModel != embeddingModel || manifest.Files == nil {
+		return empty, nil
+	}
+	return &manifest, nil
+}
+
+// 保存索引清单，先写临时文件再重命名，中途失败不会留下损坏的清单
+func saveIndexManifest(path string, manifest *IndexManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化索引清单失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建持久化目录失败: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入索引清单失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入索引清单失败: %w", err)
+	}
+	return nil
+}
+
+// 分块的点 ID：由来源路径、分块序号、内容摘要和元数据摘要决定。
+// 元数据（文档类型、标题路径、修改时间等）变化时 ID 随之变化，分块会重新写入，
+// 避免向量存储中保留过期的元数据
+func chunkID(source string, index int, chunk schema.Document) string {
+	metadata, err := json.Marshal(chunk.Metadata)
+	if err != nil {
+		metadata = []byte(fmt.Sprint(chunk.Metadata))
+	}
+	key := source + "\x00" + strconv.Itoa(index) + "\x00" + contentHash(chunk.PageContent) + "\x00" + contentHash(string(metadata))
+	return uuid.NewSHA1(chunkIDNamespace, []byte(key)).String()
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
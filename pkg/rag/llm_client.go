@@ -46,15 +46,20 @@ func NewLLMClient(config LLMConfig) (*LLMClient, error) {
 	}, nil
 }
 
-// Call 直接调用LLM
-func (c *LLMClient) Call(ctx context.Context, prompt string) (string, error) {
-	response, err := c.llm.Call(ctx, prompt)
+// Call 直接调用LLM，options 可以设置流式输出等调用选项
+func (c *LLMClient) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	response, err := c.llm.Call(ctx, prompt, options...)
 	if err != nil {
 		return "", fmt.Errorf("调用LLM失败: %w", err)
 	}
 	return response, nil
 }
 
+// Model 返回使用的模型名称
+func (c *LLMClient) Model() string {
+	return c.config.Model
+}
+
 // GetLLM 获取底层LLM
 func (c *LLMClient) GetLLM() llms.LLM {
 	return c.llm
@@ -62,8 +67,9 @@ func (c *LLMClient) GetLLM() llms.LLM {
 
 // CreateQAChain 创建问答链
 func (c *LLMClient) CreateQAChain() chains.Chain {
-	// 创建一个简单的提示模板
+	// 上下文按 [n] 编号，要求模型以相同的编号标注引用
 	template := `使用以下上下文来回答问题。如果你不知道答案，只需说不知道，不要试图编造答案。
+上下文中的每一段都以 [n] 编号。回答中用到某段上下文时，在相应句子末尾标注它的编号，如 [1] 或 [1][2]，只标注确实用到的编号。
 
 上下文:
 {{.context}}
@@ -0,0 +1,472 @@
+package rag
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 本地存储文件的格式版本
+const localStoreVersion = 1
+
+func init() {
+	// 元数据以 interface{} 保存，gob 需要预先注册具体类型
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register([]string{})
+}
+
+// 本地向量存储的检索方式
+const (
+	LocalIndexFlat = "flat" // 暴力检索，结果精确
+	LocalIndexHNSW = "hnsw" // HNSW 近似检索，适合大量分块
+)
+
+// LocalStoreConfig 本地向量存储配置
+type LocalStoreConfig struct {
+	Path  string     // 负载文件路径，HNSW 索引保存在同目录的 .hnsw 文件中
+	Index string     // 检索方式，默认为 flat
+	HNSW  HNSWConfig // Index 为 hnsw 时的索引参数
+}
+
+// LocalStore 进程内的向量存储后端，数据持久化到单个文件，
+// 适合不想额外部署向量数据库的场景。检索使用余弦相似度，可选 HNSW 近似索引
+type LocalStore struct {
+	mu         sync.RWMutex
+	path       string
+	indexPath  string
+	dim        int
+	points     map[string]*localPoint
+	index      *HNSW // 为 nil 时暴力检索
+	hnswConfig HNSWConfig
+	generation uint64
+	dirty      bool
+}
+
+type localPoint struct {
+	ID       string
+	Vector   []float32 // 已归一化，余弦相似度即点积
+	Content  string
+	Metadata map[string]interface{}
+}
+
+// 持久化文件内容
+type localStoreFile struct {
+	Version    int
+	Generation uint64 // 每次保存递增，用于判断 HNSW 索引文件是否与负载一致
+	Dim        int
+	Points     []*localPoint
+}
+
+var _ Backend = (*LocalStore)(nil)
+
+// NewLocalStore 创建本地向量存储，数据文件存在时加载已有数据
+func NewLocalStore(config LocalStoreConfig) (*LocalStore, error) {
+	s := &LocalStore{
+		path:      config.Path,
+		indexPath: strings.TrimSuffix(config.Path, filepath.Ext(config.Path)) + ".hnsw",
+		points:    make(map[string]*localPoint),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	switch config.Index {
+	case "", LocalIndexFlat:
+	case LocalIndexHNSW:
+		s.hnswConfig = config.HNSW.withDefaults()
+		if err := s.loadIndex(s.hnswConfig); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的本地检索方式: %s", config.Index)
+	}
+	return s, nil
+}
+
+func (s *LocalStore) load() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("打开本地向量存储失败: %w", err)
+	}
+	defer f.Close()
+
+	var data localStoreFile
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&data); err != nil {
+		return fmt.Errorf("读取本地向量存储 %s 失败: %w", s.path, err)
+	}
+	if data.Version != localStoreVersion {
+		return fmt.Errorf("不支持的本地向量存储版本: %d", data.Version)
+	}
+
+	s.dim = data.Dim
+	s.generation = data.Generation
+	for _, p := range data.Points {
+		s.points[p.ID] = p
+	}
+	return nil
+}
+
+// 加载 HNSW 索引，索引文件缺失、与负载版本不一致或构建参数变化时重建
+func (s *LocalStore) loadIndex(config HNSWConfig) error {
+	if f, err := os.Open(s.indexPath); err == nil {
+		index, generation, err := DecodeHNSW(bufio.NewReader(f), func(id string) ([]float32, bool) {
+			p, ok := s.points[id]
+			if !ok {
+				return nil, false
+			}
+			return p.Vector, true
+		})
+		f.Close()
+
+		built := HNSWConfig{}
+		if index != nil {
+			built = index.Config()
+		}
+		switch {
+		case err != nil:
+			log.Printf("HNSW 索引不可用，将重建: %v", err)
+		case generation != s.generation || index.Len() != len(s.points):
+			log.Printf("HNSW 索引与数据不一致，将重建")
+		case built.M != config.M || built.EfConstruction != config.EfConstruction:
+			log.Printf("HNSW 构建参数已变化，将重建")
+		default:
+			index.SetEfSearch(config.EfSearch)
+			s.index = index
+			return nil
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("打开 HNSW 索引失败: %w", err)
+	}
+
+	start := time.Now()
+	s.index = NewHNSW(config)
+	// 按 ID 排序插入，重建结果稳定
+	ids := make([]string, 0, len(s.points))
+	for id := range s.points {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		s.index.Add(id, s.points[id].Vector)
+	}
+	if len(ids) > 0 {
+		log.Printf("已重建 HNSW 索引，共 %d 个分块，耗时 %s", len(ids), time.Since(start).Round(time.Millisecond))
+		s.dirty = true
+	}
+	return nil
+}
+
+// Flush 把数据和 HNSW 索引写入磁盘，删除的分块过多时先重建索引
+func (s *LocalStore) Flush(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+
+	if s.index != nil && s.index.NeedsRebuild() {
+		s.index.Rebuild()
+	}
+
+	// 先写负载再写索引，两者之间中断时索引的版本号落后，下次加载会重建索引
+	generation := s.generation + 1
+	data := localStoreFile{Version: localStoreVersion, Generation: generation, Dim: s.dim}
+	for _, p := range s.points {
+		data.Points = append(data.Points, p)
+	}
+	if err := writeFileAtomic(s.path, func(w io.Writer) error {
+		return gob.NewEncoder(w).Encode(&data)
+	}); err != nil {
+		return fmt.Errorf("写入本地向量存储失败: %w", err)
+	}
+	s.generation = generation
+
+	if s.index != nil {
+		if err := writeFileAtomic(s.indexPath, func(w io.Writer) error {
+			return s.index.Encode(w, generation)
+		}); err != nil {
+			return fmt.Errorf("写入 HNSW 索引失败: %w", err)
+		}
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// 先写同目录的临时文件并同步，再重命名替换，中途退出时磁盘上始终是完整的旧文件或新文件
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := write(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	// 同步目录项，保证重命名本身落盘
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// CreateCollection 记录向量维度，已有数据的维度不同时报错
+func (s *LocalStore) CreateCollection(_ context.Context, dim int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dim != 0 && s.dim != dim {
+		return fmt.Errorf("向量维度 %d 与已有数据的维度 %d 不一致，请删除 %s 后重建", dim, s.dim, s.path)
+	}
+	s.dim = dim
+	return nil
+}
+
+// Upsert 按 ID 写入点，需要调用 Flush 持久化
+func (s *LocalStore) Upsert(_ context.Context, points []Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range points {
+		if s.dim == 0 {
+			s.dim = len(p.Vector)
+		}
+		if len(p.Vector) != s.dim {
+			return fmt.Errorf("向量维度 %d 与已有数据的维度 %d 不一致，请删除 %s 后重建", len(p.Vector), s.dim, s.path)
+		}
+		metadata := make(map[string]interface{}, len(p.Metadata))
+		for key, value := range p.Metadata {
+			metadata[key] = value
+		}
+		point := &localPoint{
+			ID:       p.ID,
+			Vector:   normalize(p.Vector),
+			Content:  p.Content,
+			Metadata: metadata,
+		}
+		s.points[p.ID] = point
+		if s.index != nil {
+			s.index.Add(point.ID, point.Vector)
+		}
+	}
+	if len(points) > 0 {
+		s.dirty = true
+	}
+	return nil
+}
+
+// Delete 删除满足过滤条件的点，需要调用 Flush 持久化
+func (s *LocalStore) Delete(_ context.Context, filter Filter) error {
+	if filter.Empty() {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, p := range s.points {
+		if !filter.matches(id, p.Metadata) {
+			continue
+		}
+		delete(s.points, id)
+		if s.index != nil {
+			s.index.Delete(id)
+		}
+		s.dirty = true
+	}
+	return nil
+}
+
+// Count 返回点的数量
+func (s *LocalStore) Count(context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.points), nil
+}
+
+// Drop 清空数据并删除数据文件和索引文件
+func (s *LocalStore) Drop(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, path := range []string{s.path, s.indexPath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除本地向量存储失败: %w", err)
+		}
+	}
+	s.points = make(map[string]*localPoint)
+	s.dim = 0
+	s.generation = 0
+	s.dirty = false
+	if s.index != nil {
+		s.index = NewHNSW(s.hnswConfig)
+	}
+	return nil
+}
+
+// Close 持久化尚未落盘的写入
+func (s *LocalStore) Close() error {
+	return s.Flush(context.Background())
+}
+
+// Search 按余弦相似度返回最相近的 k 个点
+func (s *LocalStore) Search(_ context.Context, vector []float32, k int, filter Filter) ([]ScoredPoint, error) {
+	vector = normalize(vector)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.dim != 0 && len(vector) != s.dim {
+		return nil, fmt.Errorf("查询向量维度 %d 与存储的维度 %d 不一致", len(vector), s.dim)
+	}
+
+	// 有 HNSW 索引且不需要过滤时走近似检索，过滤条件下退回精确检索以保证数量
+	if s.index != nil && filter.Empty() {
+		var results []ScoredPoint
+		for _, r := range s.index.Search(vector, k, 0) {
+			results = append(results, s.points[r.ID].scored(r.Score))
+		}
+		return results, nil
+	}
+
+	// 用小顶堆保留得分最高的 k 个点
+	h := &scoredHeap{}
+	for id, p := range s.points {
+		if !filter.matches(id, p.Metadata) {
+			continue
+		}
+		score := dot(vector, p.Vector)
+		if h.Len() < k {
+			heap.Push(h, scoredPoint{point: p, score: score})
+		} else if k > 0 && score > (*h)[0].score {
+			(*h)[0] = scoredPoint{point: p, score: score}
+			heap.Fix(h, 0)
+		}
+	}
+
+	results := make([]ScoredPoint, h.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		sp := heap.Pop(h).(scoredPoint)
+		results[i] = sp.point.scored(sp.score)
+	}
+	return results, nil
+}
+
+// 转换为检索结果，元数据复制一份避免调用方修改存储中的数据
+func (p *localPoint) scored(score float32) ScoredPoint {
+	metadata := make(map[string]interface{}, len(p.Metadata))
+	for key, value := range p.Metadata {
+		metadata[key] = value
+	}
+	return ScoredPoint{Point: Point{ID: p.ID, Content: p.Content, Metadata: metadata}, Score: score}
+}
+
+// 元数据等值过滤，过滤值为切片时匹配其中任意一个
+func matchFilters(metadata, filters map[string]interface{}) bool {
+	for key, want := range filters {
+		got, ok := metadata[key]
+		if !ok {
+			return false
+		}
+		if values, isSlice := want.([]interface{}); isSlice {
+			matched := false
+			for _, v := range values {
+				if reflect.DeepEqual(got, v) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		} else if !reflect.DeepEqual(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if sum == 0 {
+		return out
+	}
+	norm := float32(math.Sqrt(sum))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+// 向量点积，按 4 路展开以减少边界检查，HNSW 构建和检索的耗时主要在这里
+func dot(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+type scoredPoint struct {
+	point *localPoint
+	score float32
+}
+
+// scoredHeap 按得分排序的小顶堆
+type scoredHeap []scoredPoint
+
+func (h scoredHeap) Len() int            { return len(h) }
+func (h scoredHeap) Less(i, j int) bool  { return h[i].score < h[j].score }
+func (h scoredHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *scoredHeap) Push(x interface{}) { *h = append(*h, x.(scoredPoint)) }
+func (h *scoredHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,82 @@
+package rag_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"enum_tools/pkg/rag"
+	"enum_tools/pkg/rag/backendtest"
+)
+
+// 对后端运行契约检查，任何一项失败都报告
+func runContract(t *testing.T, b rag.Backend) {
+	t.Helper()
+	defer b.Close()
+	for _, r := range backendtest.Run(context.Background(), b) {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Name, r.Err)
+		}
+	}
+}
+
+func TestLocalStoreContract(t *testing.T) {
+	for _, index := range []string{rag.LocalIndexFlat, rag.LocalIndexHNSW} {
+		t.Run(index, func(t *testing.T) {
+			store, err := rag.NewLocalStore(rag.LocalStoreConfig{
+				Path:  filepath.Join(t.TempDir(), "vectors.gob"),
+				Index: index,
+				HNSW:  rag.DefaultHNSWConfig(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runContract(t, store)
+		})
+	}
+}
+
+// 远程后端的检查需要可用的服务，未设置地址时跳过
+
+func TestQdrantContract(t *testing.T) {
+	url := os.Getenv("DOCGEN_TEST_QDRANT_URL")
+	if url == "" {
+		t.Skip("未设置 DOCGEN_TEST_QDRANT_URL")
+	}
+	runContract(t, rag.NewQdrantBackend(url, "docgen_contract_test"))
+}
+
+func TestChromaContract(t *testing.T) {
+	url := os.Getenv("DOCGEN_TEST_CHROMA_URL")
+	if url == "" {
+		t.Skip("未设置 DOCGEN_TEST_CHROMA_URL")
+	}
+	runContract(t, rag.NewChromaBackend(url, "docgen_contract_test"))
+}
+
+// pgvector 默认使用 pgx 驱动，需要以 -tags pgx 运行测试
+func TestPGVectorContract(t *testing.T) {
+	dsn := os.Getenv("DOCGEN_TEST_PG_DSN")
+	if dsn == "" {
+		t.Skip("未设置 DOCGEN_TEST_PG_DSN")
+	}
+	driver := os.Getenv("DOCGEN_TEST_PG_DRIVER")
+	if driver == "" {
+		driver = "pgx"
+	}
+	registered := false
+	for _, name := range sql.Drivers() {
+		registered = registered || name == driver
+	}
+	if !registered {
+		t.Skipf("数据库驱动 %s 未注册", driver)
+	}
+
+	b, err := rag.NewPGVectorBackend(driver, dsn, "contract_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runContract(t, b)
+}
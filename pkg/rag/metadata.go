@@ -0,0 +1,163 @@
+package rag
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 分块元数据的键
+const (
+	MetaSource   = "source"   // 文档文件路径
+	MetaModified = "modified" // 文档文件的修改时间，Unix 秒
+	MetaDocType  = "doc_type" // 文档类型：enum、table、api_schema、error_code、route、config
+	MetaProject  = "project"  // 所属项目，多仓库生成的文档才有
+	MetaPackage  = "package"  // 枚举或配置所在的包
+	MetaSchema   = "schema"   // 数据库表的 schema，接口数据模型为所属文档标题
+	MetaCategory = "category" // 枚举分类
+	MetaName     = "name"     // 枚举、表等的名称
+	MetaHeadings = "headings" // 分块所在的标题路径，如“数据库表 > order_details（订单详情表）”
+)
+
+// FilterKeys 可以用于过滤检索的元数据键，对应 Qdrant 中建立了负载索引的字段
+var FilterKeys = []string{MetaSource, MetaDocType, MetaProject, MetaPackage, MetaSchema, MetaCategory, MetaName}
+
+// docgen 生成的 Markdown 中的元数据注释，形如 <!-- docgen: {"doc_type":"enum","package":"mail"} -->，
+// 每条注释开始一个新的章节
+const (
+	docgenMetaPrefix = "<!-- docgen: "
+	docgenMetaSuffix = "-->"
+)
+
+// 按元数据注释切分出的章节
+type docSection struct {
+	Metadata map[string]string
+	Text     string
+}
+
+// 按 docgen 的元数据注释把文档切分为章节，注释行本身不保留。
+// 第一条注释之前的内容和没有注释的文档作为一个没有附加元数据的章节；
+// 只有标题的章节（如“# 数据库表”）不单独成块，标题并入下一个章节
+func splitSections(content string) []docSection {
+	var sections []docSection
+	current := docSection{}
+	var text strings.Builder
+	flush := func() {
+		if strings.TrimSpace(text.String()) == "" {
+			text.Reset()
+			return
+		}
+		if onlyHeadings(text.String()) {
+			return
+		}
+		current.Text = text.String()
+		sections = append(sections, current)
+		text.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if meta, ok := parseDocgenMeta(line); ok {
+			flush()
+			current = docSection{Metadata: meta}
+			continue
+		}
+		text.WriteString(line)
+	}
+	flush()
+	// 文档以只有标题的章节结尾时仍然保留
+	if text.Len() > 0 {
+		current.Text = text.String()
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+// 判断文本是否只有 Markdown 标题
+func onlyHeadings(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// 解析一行元数据注释
+func parseDocgenMeta(line string) (map[string]string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, docgenMetaPrefix) || !strings.HasSuffix(line, docgenMetaSuffix) {
+		return nil, false
+	}
+	data := strings.TrimSuffix(strings.TrimPrefix(line, docgenMetaPrefix), docgenMetaSuffix)
+	var meta map[string]string
+	if err := json.Unmarshal([]byte(data), &meta); err != nil {
+		return nil, false
+	}
+	return meta, true
+}
+
+// ParseFilter 解析 key=value 形式的过滤条件，如 project=billing、doc_type=table。
+// 值中用逗号分隔的多个值匹配其中任意一个，多个条件需要同时满足，同一个键出现多次时合并取值
+func ParseFilter(exprs ...string) (Filter, error) {
+	values := make(map[string][]interface{})
+	for _, expr := range exprs {
+		key, value, ok := strings.Cut(expr, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return Filter{}, fmt.Errorf("过滤条件 %q 的格式应为 key=value", expr)
+		}
+		if !validFilterKey(key) {
+			return Filter{}, fmt.Errorf("不支持的过滤字段 %s，可用字段: %s", key, strings.Join(FilterKeys, "、"))
+		}
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values[key] = append(values[key], v)
+			}
+		}
+		if len(values[key]) == 0 {
+			return Filter{}, fmt.Errorf("过滤条件 %q 缺少取值", expr)
+		}
+	}
+
+	filter := Filter{}
+	if len(values) == 0 {
+		return filter, nil
+	}
+	filter.Match = make(map[string]interface{}, len(values))
+	for key, vs := range values {
+		if len(vs) == 1 {
+			filter.Match[key] = vs[0]
+		} else {
+			filter.Match[key] = vs
+		}
+	}
+	return filter, nil
+}
+
+func validFilterKey(key string) bool {
+	for _, k := range FilterKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回过滤条件的可读形式，与 ParseFilter 的输入格式一致
+func (f Filter) String() string {
+	parts := make([]string, 0, len(f.Match))
+	for key, value := range f.Match {
+		if values, ok := value.([]interface{}); ok {
+			strs := make([]string, len(values))
+			for i, v := range values {
+				strs[i] = fmt.Sprint(v)
+			}
+			parts = append(parts, key+"="+strings.Join(strs, ","))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s=%v", key, value))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,96 @@
+package rag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		exprs   []string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{"无条件", nil, nil, false},
+		{"单个取值", []string{"doc_type=enum"}, map[string]interface{}{"doc_type": "enum"}, false},
+		{"去掉空白", []string{" project = billing "}, map[string]interface{}{"project": "billing"}, false},
+		{"多个取值", []string{"doc_type=table,api_schema"}, map[string]interface{}{"doc_type": []interface{}{"table", "api_schema"}}, false},
+		{"重复的键合并", []string{"doc_type=enum", "doc_type=table"}, map[string]interface{}{"doc_type": []interface{}{"enum", "table"}}, false},
+		{"忽略空的取值", []string{"package=mail,,"}, map[string]interface{}{"package": "mail"}, false},
+		{"多个键", []string{"project=billing", "doc_type=table"}, map[string]interface{}{"project": "billing", "doc_type": "table"}, false},
+		{"缺少取值", []string{"doc_type="}, nil, true},
+		{"只有分隔符", []string{"doc_type= , "}, nil, true},
+		{"缺少等号", []string{"doc_type"}, nil, true},
+		{"缺少键", []string{"=enum"}, nil, true},
+		{"不支持的键", []string{"headings=枚举"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseFilter(tt.exprs...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，得到 %v", filter.Match)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(filter.Match, tt.want) {
+				t.Fatalf("Match = %#v，期望 %#v", filter.Match, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []docSection
+	}{
+		{
+			name:    "没有元数据注释",
+			content: "# 标题\n\n正文\n",
+			want:    []docSection{{Text: "# 标题\n\n正文\n"}},
+		},
+		{
+			name:    "注释之前的内容单独成节",
+			content: "前言\n<!-- docgen: {\"doc_type\":\"enum\"} -->\n# 枚举\n\nMailStatus\n",
+			want: []docSection{
+				{Text: "前言\n"},
+				{Metadata: map[string]string{"doc_type": "enum"}, Text: "# 枚举\n\nMailStatus\n"},
+			},
+		},
+		{
+			name: "只有标题的章节并入下一节",
+			content: "<!-- docgen: {\"doc_type\":\"table\"} -->\n# 数据库表\n\n" +
+				"<!-- docgen: {\"doc_type\":\"table\",\"name\":\"orders\"} -->\n## orders\n\n| 字段 |\n",
+			want: []docSection{
+				{Metadata: map[string]string{"doc_type": "table", "name": "orders"}, Text: "# 数据库表\n\n## orders\n\n| 字段 |\n"},
+			},
+		},
+		{
+			name:    "以只有标题的章节结尾",
+			content: "<!-- docgen: {\"doc_type\":\"enum\"} -->\nMailStatus\n<!-- docgen: {\"doc_type\":\"route\"} -->\n# 接口路由\n",
+			want: []docSection{
+				{Metadata: map[string]string{"doc_type": "enum"}, Text: "MailStatus\n"},
+				{Metadata: map[string]string{"doc_type": "route"}, Text: "# 接口路由\n"},
+			},
+		},
+		{
+			name:    "空文档",
+			content: "",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitSections(tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitSections = %#v，期望 %#v", got, tt.want)
+			}
+		})
+	}
+}
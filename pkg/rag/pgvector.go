@@ -0,0 +1,270 @@
+package rag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pgvector 默认使用的 database/sql 驱动名
+const defaultPGDriver = "pgx"
+
+// 集合名中不能出现在表名里的字符
+var pgIdentifierReplacer = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// PGVectorBackend 使用 PostgreSQL 的 pgvector 扩展保存向量，每个集合一张表。
+// 通过 database/sql 访问，驱动需要由程序注册，见 pgx_driver.go
+type PGVectorBackend struct {
+	db    *sql.DB
+	table string
+}
+
+var _ Backend = (*PGVectorBackend)(nil)
+
+// NewPGVectorBackend 连接数据库，driver 为空时使用 pgx
+func NewPGVectorBackend(driver, dsn, collection string) (*PGVectorBackend, error) {
+	if driver == "" {
+		driver = defaultPGDriver
+	}
+	if dsn == "" {
+		return nil, fmt.Errorf("pgvector 需要设置数据库连接串")
+	}
+	if !driverRegistered(driver) {
+		return nil, fmt.Errorf("数据库驱动 %s 未注册，使用 pgx 时请以 -tags pgx 编译", driver)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+	return &PGVectorBackend{
+		db:    db,
+		table: "docgen_" + strings.ToLower(pgIdentifierReplacer.ReplaceAllString(collection, "_")),
+	}, nil
+}
+
+func driverRegistered(name string) bool {
+	for _, driver := range sql.Drivers() {
+		if driver == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateCollection 创建扩展、表、HNSW 索引和元数据索引，已存在时检查向量维度
+func (b *PGVectorBackend) CreateCollection(ctx context.Context, dim int) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			content TEXT NOT NULL,
+			metadata JSONB NOT NULL DEFAULT '{}',
+			embedding vector(%d) NOT NULL
+		)`, b.table, dim),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_embedding_idx ON %s USING hnsw (embedding vector_cosine_ops)`, b.table, b.table),
+		// 元数据过滤使用 @> 条件，jsonb_path_ops 索引只支持包含查询但体积更小
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_metadata_idx ON %s USING gin (metadata jsonb_path_ops)`, b.table, b.table),
+	}
+	for _, stmt := range statements {
+		if _, err := b.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("创建集合失败: %w", err)
+		}
+	}
+
+	// 表已存在时维度以建表时为准
+	var existing int
+	err := b.db.QueryRowContext(ctx, `SELECT atttypmod FROM pg_attribute
+		WHERE attrelid = $1::regclass AND attname = 'embedding'`, b.table).Scan(&existing)
+	if err != nil {
+		return fmt.Errorf("查询向量维度失败: %w", err)
+	}
+	if existing > 0 && existing != dim {
+		return fmt.Errorf("表 %s 的向量维度为 %d，与嵌入模型的维度 %d 不一致", b.table, existing, dim)
+	}
+	return nil
+}
+
+// Upsert 在一个事务中写入点
+func (b *PGVectorBackend) Upsert(ctx context.Context, points []Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s (id, content, metadata, embedding)
+		VALUES ($1, $2, $3::jsonb, $4::vector)
+		ON CONFLICT (id) DO UPDATE SET content = EXCLUDED.content, metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding`, b.table))
+	if err != nil {
+		return fmt.Errorf("写入向量失败: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range points {
+		metadata, err := json.Marshal(p.Metadata)
+		if err != nil {
+			return fmt.Errorf("序列化元数据失败: %w", err)
+		}
+		if p.Metadata == nil {
+			metadata = []byte("{}")
+		}
+		if _, err := stmt.ExecContext(ctx, p.ID, p.Content, string(metadata), pgVector(p.Vector)); err != nil {
+			return fmt.Errorf("写入向量失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除满足过滤条件的点
+func (b *PGVectorBackend) Delete(ctx context.Context, filter Filter) error {
+	if filter.Empty() {
+		return nil
+	}
+	where, args, err := pgWhere(filter, 1)
+	if err != nil {
+		return err
+	}
+	if _, err := b.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s`, b.table, where), args...); err != nil {
+		return fmt.Errorf("删除向量失败: %w", err)
+	}
+	return nil
+}
+
+// Search 按余弦距离检索最相近的 k 个点
+func (b *PGVectorBackend) Search(ctx context.Context, vector []float32, k int, filter Filter) ([]ScoredPoint, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	where, args, err := pgWhere(filter, 3)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`SELECT id, content, metadata, 1 - (embedding <=> $1::vector) AS score
+		FROM %s WHERE %s ORDER BY embedding <=> $1::vector LIMIT $2`, b.table, where)
+	rows, err := b.db.QueryContext(ctx, query, append([]interface{}{pgVector(vector), k}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("检索向量失败: %w", err)
+	}
+	defer rows.Close()
+
+	var results []ScoredPoint
+	for rows.Next() {
+		var p ScoredPoint
+		var metadata []byte
+		var score float64
+		if err := rows.Scan(&p.ID, &p.Content, &metadata, &score); err != nil {
+			return nil, fmt.Errorf("读取检索结果失败: %w", err)
+		}
+		if err := json.Unmarshal(metadata, &p.Metadata); err != nil {
+			return nil, fmt.Errorf("解析元数据失败: %w", err)
+		}
+		p.Score = float32(score)
+		results = append(results, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取检索结果失败: %w", err)
+	}
+	return results, nil
+}
+
+// Count 返回表中的行数
+func (b *PGVectorBackend) Count(ctx context.Context) (int, error) {
+	var count int
+	if err := b.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %s`, b.table)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("统计向量数量失败: %w", err)
+	}
+	return count, nil
+}
+
+// Drop 删除表
+func (b *PGVectorBackend) Drop(ctx context.Context) error {
+	if _, err := b.db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, b.table)); err != nil {
+		return fmt.Errorf("删除集合失败: %w", err)
+	}
+	return nil
+}
+
+// Flush 写入在事务提交时已完成
+func (b *PGVectorBackend) Flush(context.Context) error { return nil }
+
+// Close 关闭数据库连接
+func (b *PGVectorBackend) Close() error {
+	return b.db.Close()
+}
+
+// 向量的文本形式，如 [0.1,0.2]，由 SQL 转换为 vector 类型
+func pgVector(v []float32) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// 转换为 SQL 条件，参数从 $start 开始编号；等值条件用 jsonb 包含判断，可以使用 GIN 索引
+func pgWhere(filter Filter, start int) (string, []interface{}, error) {
+	keys := make([]string, 0, len(filter.Match))
+	for key := range filter.Match {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var conditions []string
+	var args []interface{}
+	contains := func(key string, value interface{}) (string, error) {
+		data, err := json.Marshal(map[string]interface{}{key: value})
+		if err != nil {
+			return "", fmt.Errorf("序列化过滤条件失败: %w", err)
+		}
+		args = append(args, string(data))
+		return fmt.Sprintf("metadata @> $%d::jsonb", start+len(args)-1), nil
+	}
+	for _, key := range keys {
+		values, ok := filter.Match[key].([]interface{})
+		if !ok {
+			values = []interface{}{filter.Match[key]}
+		}
+		var alternatives []string
+		for _, value := range values {
+			cond, err := contains(key, value)
+			if err != nil {
+				return "", nil, err
+			}
+			alternatives = append(alternatives, cond)
+		}
+		if len(alternatives) == 0 {
+			alternatives = append(alternatives, "FALSE")
+		}
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+	if len(filter.ExcludeIDs) > 0 {
+		placeholders := make([]string, len(filter.ExcludeIDs))
+		for i, id := range filter.ExcludeIDs {
+			args = append(args, id)
+			placeholders[i] = fmt.Sprintf("$%d", start+len(args)-1)
+		}
+		conditions = append(conditions, "id NOT IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	if len(conditions) == 0 {
+		return "TRUE", nil, nil
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
@@ -0,0 +1,7 @@
+//go:build pgx
+
+package rag
+
+// 以 -tags pgx 编译时注册 pgx 驱动，供 pgvector 后端使用；
+// 默认编译不依赖 PostgreSQL 驱动，也可以由程序自行导入其他驱动并设置 driver
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,263 @@
+package rag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Qdrant 负载中保存分块正文的键，与 langchaingo 的 qdrant 存储保持一致，
+// 早期版本写入的数据可以继续检索
+const qdrantContentKey = "content"
+
+// QdrantBackend 通过 REST 接口访问 Qdrant 集合
+type QdrantBackend struct {
+	url        string
+	collection string
+	client     *http.Client
+}
+
+var _ Backend = (*QdrantBackend)(nil)
+
+// NewQdrantBackend 创建 Qdrant 后端，url 为服务地址，如 http://localhost:6333
+func NewQdrantBackend(url, collection string) *QdrantBackend {
+	return &QdrantBackend{
+		url:        strings.TrimRight(url, "/"),
+		collection: collection,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateCollection 创建使用余弦距离的集合，已存在时检查向量维度
+func (b *QdrantBackend) CreateCollection(ctx context.Context, dim int) error {
+	var info struct {
+		Config struct {
+			Params struct {
+				Vectors struct {
+					Size int `json:"size"`
+				} `json:"vectors"`
+			} `json:"params"`
+		} `json:"config"`
+	}
+	status, err := b.request(ctx, http.MethodGet, "/collections/"+b.collection, nil, &info)
+	if err != nil && status != http.StatusNotFound {
+		return fmt.Errorf("查询集合失败: %w", err)
+	}
+	if err == nil {
+		if size := info.Config.Params.Vectors.Size; size != 0 && size != dim {
+			return fmt.Errorf("集合 %s 的向量维度为 %d，与嵌入模型的维度 %d 不一致", b.collection, size, dim)
+		}
+		// 早期版本创建的集合没有负载索引，补建
+		return b.createPayloadIndexes(ctx)
+	}
+
+	body := map[string]interface{}{
+		"vectors": map[string]interface{}{
+			"size":     dim,
+			"distance": "Cosine",
+		},
+		"optimizers_config": map[string]interface{}{
+			"default_segment_number": 2,
+		},
+		"replication_factor":       1,
+		"write_consistency_factor": 1,
+	}
+	if _, err := b.request(ctx, http.MethodPut, "/collections/"+b.collection, body, nil); err != nil {
+		return fmt.Errorf("创建集合失败: %w", err)
+	}
+	log.Printf("成功创建集合 %s", b.collection)
+	return b.createPayloadIndexes(ctx)
+}
+
+// 为可过滤的元数据字段建立负载索引，过滤检索时不需要扫描全部负载。索引已存在时请求同样成功
+func (b *QdrantBackend) createPayloadIndexes(ctx context.Context) error {
+	fields := map[string]string{MetaModified: "integer"}
+	for _, key := range FilterKeys {
+		fields[key] = "keyword"
+	}
+	for field, schema := range fields {
+		body := map[string]interface{}{"field_name": field, "field_schema": schema}
+		if _, err := b.request(ctx, http.MethodPut, b.pointsPath("index"), body, nil); err != nil {
+			return fmt.Errorf("创建负载索引 %s 失败: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// Upsert 写入点，正文保存在负载的 content 键中
+func (b *QdrantBackend) Upsert(ctx context.Context, points []Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	ids := make([]string, len(points))
+	vectors := make([][]float32, len(points))
+	payloads := make([]map[string]interface{}, len(points))
+	for i, p := range points {
+		ids[i] = p.ID
+		vectors[i] = p.Vector
+		payload := make(map[string]interface{}, len(p.Metadata)+1)
+		for key, value := range p.Metadata {
+			payload[key] = value
+		}
+		payload[qdrantContentKey] = p.Content
+		payloads[i] = payload
+	}
+
+	body := map[string]interface{}{
+		"batch": map[string]interface{}{
+			"ids":      ids,
+			"vectors":  vectors,
+			"payloads": payloads,
+		},
+	}
+	if _, err := b.request(ctx, http.MethodPut, b.pointsPath("points"), body, nil); err != nil {
+		return fmt.Errorf("写入向量失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除满足过滤条件的点
+func (b *QdrantBackend) Delete(ctx context.Context, filter Filter) error {
+	if filter.Empty() {
+		return nil
+	}
+	body := map[string]interface{}{"filter": qdrantFilter(filter)}
+	if _, err := b.request(ctx, http.MethodPost, b.pointsPath("points/delete"), body, nil); err != nil {
+		return fmt.Errorf("删除向量失败: %w", err)
+	}
+	return nil
+}
+
+// Search 检索最相近的 k 个点
+func (b *QdrantBackend) Search(ctx context.Context, vector []float32, k int, filter Filter) ([]ScoredPoint, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	body := map[string]interface{}{
+		"vector":       vector,
+		"limit":        k,
+		"with_payload": true,
+	}
+	if !filter.Empty() {
+		body["filter"] = qdrantFilter(filter)
+	}
+
+	var hits []struct {
+		ID      interface{}            `json:"id"`
+		Score   float32                `json:"score"`
+		Payload map[string]interface{} `json:"payload"`
+	}
+	if _, err := b.request(ctx, http.MethodPost, "/collections/"+b.collection+"/points/search", body, &hits); err != nil {
+		return nil, fmt.Errorf("检索向量失败: %w", err)
+	}
+
+	results := make([]ScoredPoint, 0, len(hits))
+	for _, hit := range hits {
+		content, _ := hit.Payload[qdrantContentKey].(string)
+		delete(hit.Payload, qdrantContentKey)
+		results = append(results, ScoredPoint{
+			Point: Point{ID: fmt.Sprint(hit.ID), Content: content, Metadata: hit.Payload},
+			Score: hit.Score,
+		})
+	}
+	return results, nil
+}
+
+// Count 返回集合中点的精确数量
+func (b *QdrantBackend) Count(ctx context.Context) (int, error) {
+	var result struct {
+		Count int `json:"count"`
+	}
+	body := map[string]interface{}{"exact": true}
+	if _, err := b.request(ctx, http.MethodPost, "/collections/"+b.collection+"/points/count", body, &result); err != nil {
+		return 0, fmt.Errorf("统计向量数量失败: %w", err)
+	}
+	return result.Count, nil
+}
+
+// Drop 删除集合
+func (b *QdrantBackend) Drop(ctx context.Context) error {
+	status, err := b.request(ctx, http.MethodDelete, "/collections/"+b.collection, nil, nil)
+	if err != nil && status != http.StatusNotFound {
+		return fmt.Errorf("删除集合失败: %w", err)
+	}
+	return nil
+}
+
+// Flush Qdrant 的写入在请求返回时已完成
+func (b *QdrantBackend) Flush(context.Context) error { return nil }
+
+// Close 没有需要释放的资源
+func (b *QdrantBackend) Close() error { return nil }
+
+// 写操作的路径，等待写入完成后再返回
+func (b *QdrantBackend) pointsPath(path string) string {
+	return "/collections/" + b.collection + "/" + path + "?wait=true"
+}
+
+// 调用 Qdrant REST 接口，把响应中的 result 解析到 out，返回 HTTP 状态码
+func (b *QdrantBackend) request(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+		reader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.url+path, reader)
+	if err != nil {
+		return 0, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("发送HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var responseBody map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&responseBody)
+		return resp.StatusCode, fmt.Errorf("状态码: %d，响应: %v", resp.StatusCode, responseBody)
+	}
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+	envelope := struct {
+		Result interface{} `json:"result"`
+	}{Result: out}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return resp.StatusCode, fmt.Errorf("解析响应体失败: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// 转换为 Qdrant 的过滤条件
+func qdrantFilter(filter Filter) map[string]interface{} {
+	var must []interface{}
+	for key, value := range filter.Match {
+		match := map[string]interface{}{"value": value}
+		if values, ok := value.([]interface{}); ok {
+			match = map[string]interface{}{"any": values}
+		}
+		must = append(must, map[string]interface{}{"key": key, "match": match})
+	}
+
+	result := map[string]interface{}{}
+	if len(must) > 0 {
+		result["must"] = must
+	}
+	if len(filter.ExcludeIDs) > 0 {
+		result["must_not"] = []interface{}{map[string]interface{}{"has_id": filter.ExcludeIDs}}
+	}
+	return result
+}
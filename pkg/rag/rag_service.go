@@ -3,9 +3,13 @@ package rag
 import (
 	"context"
 	"fmt"
+	"log"
 
+	"github.com/tmc/langchaingo/chains"
 	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/openai"
+	"github.com/tmc/langchaingo/schema"
 )
 
 // RAGConfig RAG系统配置
@@ -17,6 +21,8 @@ type RAGConfig struct {
 	EmbeddingModel string
 	MaxTokens      int
 	VectorStore    VectorStoreConfig
+	Retrieval      RetrievalConfig
+	Rerank         RerankConfig
 }
 
 // RAGService RAG服务
@@ -24,7 +30,9 @@ type RAGService struct {
 	config        RAGConfig
 	docLoader     *DocumentLoader
 	vectorStore   *VectorStore
+	keywordIndex  *BM25Index
 	llmClient     *LLMClient
+	reranker      Reranker // 为 nil 时不重排
 	docsProcessed bool
 }
 
@@ -55,6 +63,12 @@ func NewRAGService(config RAGConfig) (*RAGService, error) {
 		return nil, fmt.Errorf("初始化向量存储失败: %w", err)
 	}
 
+	// 加载关键词索引，与向量存储中的分块一一对应
+	keywordIndex, err := LoadBM25Index(bm25IndexPath(config.VectorStore.PersistDir, vectorStore.config.CollectionName))
+	if err != nil {
+		return nil, fmt.Errorf("加载关键词索引失败: %w", err)
+	}
+
 	// 创建LLM客户端
 	llmClient, err := NewLLMClient(LLMConfig{
 		APIKey:    config.OpenAIKey,
@@ -66,70 +80,267 @@ func NewRAGService(config RAGConfig) (*RAGService, error) {
 		return nil, fmt.Errorf("初始化LLM客户端失败: %w", err)
 	}
 
+	if action := config.Retrieval.withDefaults().NoContext; !ValidNoContext(action) {
+		return nil, fmt.Errorf("不支持的无上下文处理方式: %s", action)
+	}
+
+	// 创建重排器
+	reranker, err := NewReranker(config.Rerank, llmClient)
+	if err != nil {
+		return nil, fmt.Errorf("初始化重排器失败: %w", err)
+	}
+
 	return &RAGService{
-		config:      config,
-		docLoader:   docLoader,
-		vectorStore: vectorStore,
-		llmClient:   llmClient,
+		config:       config,
+		docLoader:    docLoader,
+		vectorStore:  vectorStore,
+		keywordIndex: keywordIndex,
+		llmClient:    llmClient,
+		reranker:     reranker,
 	}, nil
 }
 
-// LoadAndProcessDocuments 加载并处理文档
-func (s *RAGService) LoadAndProcessDocuments(ctx context.Context) error {
+// LoadAndProcessDocuments 加载文档并增量写入向量存储和关键词索引：分块使用确定的 ID，
+// 只写入新增或变化的分块，删除已删除文件和变化文件的旧分块，
+// 已索引的内容记录在 PersistDir 下的索引清单中
+func (s *RAGService) LoadAndProcessDocuments(ctx context.Context) (IndexStats, error) {
+	var stats IndexStats
 
 	// 加载文档
 	docs, err := s.docLoader.LoadDocuments(ctx)
 	if err != nil {
-		return fmt.Errorf("加载文档失败: %w", err)
+		return stats, fmt.Errorf("加载文档失败: %w", err)
 	}
 
-	// 分割文档
-	splitDocs, err := s.docLoader.SplitDocuments(docs)
+	collection := s.vectorStore.config.CollectionName
+	manifestPath := indexManifestPath(s.config.VectorStore.PersistDir, collection)
+	keywordPath := bm25IndexPath(s.config.VectorStore.PersistDir, collection)
+	manifest, err := loadIndexManifest(manifestPath, collection, s.config.EmbeddingModel)
 	if err != nil {
-		return fmt.Errorf("分割文档失败: %w", err)
+		return stats, err
 	}
-
-	// 添加到向量存储
-	err = s.vectorStore.AddDocuments(ctx, splitDocs)
+	// 向量存储中的分块数与清单不一致（如集合被删除或清空、清单来自其他环境）时清空集合重建索引，
+	// 否则清单中未变化的文件永远不会重新写入
+	count, err := s.vectorStore.Backend().Count(ctx)
 	if err != nil {
-		return fmt.Errorf("添加文档到向量存储失败: %w", err)
+		return stats, err
+	}
+	if total := manifest.chunkCount(); count != total {
+		log.Printf("向量存储中有 %d 个分块，索引清单记录了 %d 个，重新建立索引", count, total)
+		if err := s.vectorStore.Reset(ctx); err != nil {
+			return stats, err
+		}
+		s.keywordIndex.Reset()
+		manifest = newIndexManifest(collection, s.config.EmbeddingModel)
+	}
+	// 先持久化向量存储和关键词索引再保存清单，清单中的分块一定已经写入
+	save := func() error {
+		if err := s.vectorStore.Flush(ctx); err != nil {
+			return err
+		}
+		if err := s.keywordIndex.Save(keywordPath); err != nil {
+			return err
+		}
+		return saveIndexManifest(manifestPath, manifest)
+	}
+	// 中途失败时也保存已完成的部分，下次只需处理剩余文件
+	fail := func(err error) (IndexStats, error) {
+		if saveErr := save(); saveErr != nil {
+			log.Printf("保存索引失败: %v", saveErr)
+		}
+		return stats, err
+	}
+
+	seen := make(map[string]bool)
+	for _, doc := range docs {
+		source, _ := doc.Metadata[MetaSource].(string)
+		seen[source] = true
+
+		hash := contentHash(doc.PageContent)
+		old, indexed := manifest.Files[source]
+		if indexed && old.Hash == hash {
+			// 关键词索引缺失（如升级前建立的索引）时只补建关键词索引，不重新生成向量
+			if s.keywordIndex.hasAll(old.Chunks) {
+				stats.Unchanged++
+				continue
+			}
+			chunks, err := s.docLoader.SplitDocuments([]schema.Document{doc})
+			if err != nil {
+				return fail(fmt.Errorf("分割文档失败: %w", err))
+			}
+			// 沿用清单中的 ID，与向量存储保持一致；修改时间等元数据变化会使重新计算的 ID 不同
+			if len(chunks) == len(old.Chunks) {
+				for i, chunk := range chunks {
+					s.keywordIndex.Add(old.Chunks[i], chunk.PageContent, chunk.Metadata)
+				}
+				stats.Unchanged++
+				continue
+			}
+			// 分块数与清单不一致时按内容变化的文件重新写入
+		}
+
+		// 分割文档
+		chunks, err := s.docLoader.SplitDocuments([]schema.Document{doc})
+		if err != nil {
+			return fail(fmt.Errorf("分割文档失败: %w", err))
+		}
+
+		existing := make(map[string]bool, len(old.Chunks))
+		for _, id := range old.Chunks {
+			existing[id] = true
+		}
+		ids := make([]string, len(chunks))
+		var newIDs []string
+		var newChunks []schema.Document
+		for i, chunk := range chunks {
+			ids[i] = chunkID(source, i, chunk)
+			if existing[ids[i]] {
+				delete(existing, ids[i])
+				continue
+			}
+			newIDs = append(newIDs, ids[i])
+			newChunks = append(newChunks, chunk)
+		}
+
+		if err := s.vectorStore.UpsertDocuments(ctx, newIDs, newChunks); err != nil {
+			return fail(fmt.Errorf("添加文档到向量存储失败: %w", err))
+		}
+		// 同时清理清单之外的旧数据，如早期版本重复写入的点
+		if err := s.vectorStore.DeleteBySource(ctx, source, ids); err != nil {
+			return fail(err)
+		}
+		for i, chunk := range chunks {
+			s.keywordIndex.Add(ids[i], chunk.PageContent, chunk.Metadata)
+		}
+		s.keywordIndex.Delete(Filter{Match: map[string]interface{}{MetaSource: source}, ExcludeIDs: ids})
+
+		stats.Upserted += len(newIDs)
+		stats.Removed += len(existing)
+		if indexed {
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+		manifest.Files[source] = IndexedFile{Hash: hash, Chunks: ids}
+	}
+
+	// 删除已不存在的文件
+	for source, file := range manifest.Files {
+		if seen[source] {
+			continue
+		}
+		if err := s.vectorStore.DeleteBySource(ctx, source, nil); err != nil {
+			return fail(err)
+		}
+		s.keywordIndex.Delete(Filter{Match: map[string]interface{}{MetaSource: source}})
+		stats.Deleted++
+		stats.Removed += len(file.Chunks)
+		delete(manifest.Files, source)
+	}
+
+	if err := save(); err != nil {
+		return stats, err
 	}
 
 	s.docsProcessed = true
-	return nil
+	return stats, nil
+}
+
+// StreamFunc 接收流式输出的回答片段，返回错误时停止生成
+type StreamFunc func(token string) error
+
+// Query 查询RAG系统，检索方式和元数据过滤条件可以通过 WithRetrievalMode、WithFilter 指定。
+// 回答中以 [n] 标注引用的上下文，Sources 中是对应的文件、标题路径和摘录
+func (s *RAGService) Query(ctx context.Context, query string, options ...QueryOption) (*Answer, error) {
+	return s.query(ctx, query, nil, options...)
+}
+
+// QueryStream 与 Query 相同，但回答生成过程中每收到一个片段就调用一次 stream，
+// 返回的 Answer 包含完整回答和来源。取消 ctx 会中断检索或生成并返回 ctx 的错误
+func (s *RAGService) QueryStream(ctx context.Context, query string, stream StreamFunc, options ...QueryOption) (*Answer, error) {
+	return s.query(ctx, query, stream, options...)
 }
 
-// Query 查询RAG系统
-func (s *RAGService) Query(ctx context.Context, query string) (string, error) {
+func (s *RAGService) query(ctx context.Context, query string, stream StreamFunc, options ...QueryOption) (*Answer, error) {
 	if !s.docsProcessed {
-		return "", fmt.Errorf("文档尚未处理，请先调用LoadAndProcessDocuments")
+		return nil, fmt.Errorf("文档尚未处理，请先调用LoadAndProcessDocuments")
 	}
 
-	// 执行相似性搜索
-	docs, err := s.vectorStore.SimilaritySearch(ctx, query, 5)
+	// 检索相关分块
+	docs, err := s.Retrieve(ctx, query, options...)
 	if err != nil {
-		return "", fmt.Errorf("相似性搜索失败: %w", err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("检索失败: %w", err)
+	}
+
+	var llmOptions []llms.CallOption
+	var chainOptions []chains.ChainCallOption
+	if stream != nil {
+		streamingFunc := func(_ context.Context, chunk []byte) error {
+			return stream(string(chunk))
+		}
+		llmOptions = append(llmOptions, llms.WithStreamingFunc(streamingFunc))
+		chainOptions = append(chainOptions, chains.WithStreamingFunc(streamingFunc))
 	}
 
-	// 构建上下文
-	context := ""
-	for i, doc := range docs {
-		context += fmt.Sprintf("文档 %d:\n%s\n\n", i+1, doc.PageContent)
+	// 没有分块通过得分下限时不把无关内容交给语言模型
+	if len(docs) == 0 {
+		if s.config.Retrieval.withDefaults().NoContext == NoContextWarn {
+			log.Printf("没有相关分块，不带上下文回答")
+			if err := emit(stream, noContextWarning); err != nil {
+				return nil, err
+			}
+			text, err := s.llmClient.Call(ctx, query, llmOptions...)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				return nil, err
+			}
+			return &Answer{Text: noContextWarning + text, Model: s.llmClient.Model()}, nil
+		}
+		log.Printf("没有相关分块，不调用语言模型")
+		if err := emit(stream, NoContextAnswer); err != nil {
+			return nil, err
+		}
+		return &Answer{Text: NoContextAnswer}, nil
 	}
 
+	// 构建带编号的上下文
+	sources := sourcesFromDocs(docs)
+
 	// 创建问答链
 	chain := s.llmClient.CreateQAChain()
 
 	// 执行问答链
 	result, err := chain.Call(ctx, map[string]any{
-		"context":  context,
+		"context":  buildContext(sources, docs),
 		"question": query,
-	})
+	}, chainOptions...)
 	if err != nil {
-		return "", fmt.Errorf("执行问答链失败: %w", err)
+		// 被调用方取消时直接返回 ctx 的错误，便于用 errors.Is 判断
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("执行问答链失败: %w", err)
 	}
 
-	return result["text"].(string), nil
+	text := result["text"].(string)
+	return &Answer{
+		Text:    text,
+		Sources: citedSources(text, sources),
+		Model:   s.llmClient.Model(),
+	}, nil
+}
+
+// 不经过语言模型的回答也交给 stream 输出
+func emit(stream StreamFunc, text string) error {
+	if stream == nil {
+		return nil
+	}
+	return stream(text)
 }
 
 // SetDocsProcessed 设置文档处理状态
@@ -0,0 +1,287 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+// 重排方式
+const (
+	RerankNone         = "none"          // 不重排
+	RerankLexical      = "lexical"       // 按查询词覆盖率重排，不调用模型
+	RerankLLMPointwise = "llm_pointwise" // 语言模型逐个给分块打分
+	RerankLLMListwise  = "llm_listwise"  // 语言模型一次对所有分块排序
+)
+
+// 重排后保存原检索得分的元数据键
+const retrievalScoreKey = "retrieval_score"
+
+// 逐个打分时同时进行的请求数
+const pointwiseConcurrency = 4
+
+// 列表重排时每个分块放入提示词的最大字数
+const listwiseSnippetRunes = 400
+
+// RerankConfig 重排配置
+type RerankConfig struct {
+	Type       string  // 重排方式，默认为 none
+	Candidates int     // 参与重排的候选分块数
+	TopK       int     // 重排后保留的分块数
+	MinScore   float64 // 重排得分低于该值的分块被丢弃，得分范围为 0 到 1
+}
+
+// DefaultRerankConfig 返回默认的重排配置
+func DefaultRerankConfig() RerankConfig {
+	return RerankConfig{Type: RerankNone, Candidates: 30, TopK: 5}
+}
+
+// 未设置的项使用默认值
+func (c RerankConfig) withDefaults() RerankConfig {
+	def := DefaultRerankConfig()
+	if c.Type == "" {
+		c.Type = def.Type
+	}
+	if c.TopK <= 0 {
+		c.TopK = def.TopK
+	}
+	if c.Candidates < c.TopK {
+		c.Candidates = max(def.Candidates, c.TopK)
+	}
+	return c
+}
+
+// Reranker 对检索到的候选分块重新打分，返回按得分从高到低排列的分块，
+// Score 为 0 到 1 之间的重排得分
+type Reranker interface {
+	Rerank(ctx context.Context, query string, docs []schema.Document) ([]schema.Document, error)
+}
+
+// NewReranker 按配置创建重排器，类型为 none 时返回 nil
+func NewReranker(config RerankConfig, llm *LLMClient) (Reranker, error) {
+	switch config.Type {
+	case "", RerankNone:
+		return nil, nil
+	case RerankLexical:
+		return LexicalReranker{}, nil
+	case RerankLLMPointwise, RerankLLMListwise:
+		if llm == nil {
+			return nil, fmt.Errorf("重排方式 %s 需要语言模型", config.Type)
+		}
+		return &LLMReranker{client: llm, listwise: config.Type == RerankLLMListwise}, nil
+	default:
+		return nil, fmt.Errorf("不支持的重排方式: %s", config.Type)
+	}
+}
+
+// 按重排得分排序，原检索得分保存到元数据中
+func sortReranked(docs []schema.Document, scores []float32) []schema.Document {
+	result := make([]schema.Document, len(docs))
+	for i, doc := range docs {
+		metadata := make(map[string]interface{}, len(doc.Metadata)+1)
+		for key, value := range doc.Metadata {
+			metadata[key] = value
+		}
+		metadata[retrievalScoreKey] = doc.Score
+		result[i] = schema.Document{PageContent: doc.PageContent, Metadata: metadata, Score: scores[i]}
+	}
+	// 得分相同时保持检索顺序
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result
+}
+
+// LexicalReranker 按查询词在分块中的覆盖率重排，不依赖模型，可以离线使用。
+// 得分为分块包含的查询词占比，分块包含完整查询时额外加分
+type LexicalReranker struct{}
+
+// Rerank 实现 Reranker
+func (LexicalReranker) Rerank(_ context.Context, query string, docs []schema.Document) ([]schema.Document, error) {
+	terms := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		terms[term] = true
+	}
+	phrase := strings.ToLower(strings.TrimSpace(query))
+
+	scores := make([]float32, len(docs))
+	for i, doc := range docs {
+		if len(terms) == 0 {
+			continue
+		}
+		docTerms := make(map[string]bool)
+		for _, term := range Tokenize(doc.PageContent) {
+			docTerms[term] = true
+		}
+		matched := 0
+		for term := range terms {
+			if docTerms[term] {
+				matched++
+			}
+		}
+		score := 0.8 * float32(matched) / float32(len(terms))
+		if phrase != "" && strings.Contains(strings.ToLower(doc.PageContent), phrase) {
+			score += 0.2
+		}
+		scores[i] = score
+	}
+	return sortReranked(docs, scores), nil
+}
+
+// LLMReranker 使用语言模型判断分块与问题的相关度。
+// 逐个打分时每个分块调用一次模型，列表重排时只调用一次，但分块内容会被截断
+type LLMReranker struct {
+	client   *LLMClient
+	listwise bool
+}
+
+// Rerank 实现 Reranker
+func (r *LLMReranker) Rerank(ctx context.Context, query string, docs []schema.Document) ([]schema.Document, error) {
+	if len(docs) == 0 {
+		return docs, nil
+	}
+	var scores []float32
+	var err error
+	if r.listwise {
+		scores, err = r.listwiseScores(ctx, query, docs)
+	} else {
+		scores, err = r.pointwiseScores(ctx, query, docs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sortReranked(docs, scores), nil
+}
+
+// 匹配模型回答中的数字
+var numberPattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// 匹配推理模型输出的思考过程，未闭合的 <think> 一直匹配到末尾
+var thinkPattern = regexp.MustCompile(`(?is)<think>.*?(?:</think>|$)`)
+
+// 去掉回答中的思考过程，其中的数字不是结论
+func stripThinking(answer string) string {
+	return thinkPattern.ReplaceAllString(answer, "")
+}
+
+// 分母前的提示词，其后的数字是满分而不是得分
+var denominatorPrefixes = []string{"/", "out of", "满分", "总分"}
+
+// 解析逐条打分的回答，取去掉思考过程后第一个 0 到 10 的整数：
+// 提示词要求只输出分数，结论通常在最前面；小数和“8/10”“8 out of 10”“满分 10 分”中的满分不算
+func parsePointwiseScore(answer string) (int, bool) {
+	answer = stripThinking(answer)
+	for _, match := range numberPattern.FindAllStringIndex(answer, -1) {
+		start, end := match[0], match[1]
+		if isDenominator(answer[:start]) {
+			continue
+		}
+		n, err := strconv.Atoi(answer[start:end])
+		if err != nil || n > 10 {
+			continue
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// 判断数字前的文本是否以分母提示词结尾
+func isDenominator(before string) bool {
+	before = strings.ToLower(strings.TrimRight(before, " \t：:（("))
+	for _, prefix := range denominatorPrefixes {
+		if strings.HasSuffix(before, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *LLMReranker) pointwiseScores(ctx context.Context, query string, docs []schema.Document) ([]float32, error) {
+	scores := make([]float32, len(docs))
+	errs := make([]error, len(docs))
+	sem := make(chan struct{}, pointwiseConcurrency)
+	var wg sync.WaitGroup
+	for i, doc := range docs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, doc schema.Document) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			prompt := fmt.Sprintf(`判断下面的文档片段对回答问题有多大帮助，用 0 到 10 的整数打分，10 表示直接包含答案，0 表示完全无关。只输出分数。
+
+问题: %s
+
+文档片段:
+%s
+
+分数:`, query, doc.PageContent)
+			answer, err := r.client.Call(ctx, prompt)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			value, ok := parsePointwiseScore(answer)
+			if !ok {
+				log.Printf("无法解析重排得分 %q，按 0 分处理", answer)
+				return
+			}
+			scores[i] = float32(value) / 10
+		}(i, doc)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("重排失败: %w", err)
+		}
+	}
+	return scores, nil
+}
+
+func (r *LLMReranker) listwiseScores(ctx context.Context, query string, docs []schema.Document) ([]float32, error) {
+	var sb strings.Builder
+	for i, doc := range docs {
+		snippet := []rune(doc.PageContent)
+		if len(snippet) > listwiseSnippetRunes {
+			snippet = append(snippet[:listwiseSnippetRunes], []rune("……")...)
+		}
+		fmt.Fprintf(&sb, "[%d] %s\n\n", i+1, string(snippet))
+	}
+	prompt := fmt.Sprintf(`下面是 %d 个编号的文档片段。按照对回答问题的帮助从大到小排列这些片段的编号，用逗号分隔，只输出编号，与问题无关的片段不要输出。
+
+问题: %s
+
+%s排序:`, len(docs), query, sb.String())
+
+	answer, err := r.client.Call(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("重排失败: %w", err)
+	}
+
+	// 排名第 r 位（从 0 开始）的得分为 1 - r/n，未列出的片段为 0 分
+	scores := make([]float32, len(docs))
+	rank := 0
+	for _, match := range numberPattern.FindAllString(stripThinking(answer), -1) {
+		n, err := strconv.Atoi(match)
+		if err != nil || n < 1 || n > len(docs) || scores[n-1] > 0 {
+			continue
+		}
+		scores[n-1] = 1 - float32(rank)/float32(len(docs))
+		rank++
+	}
+	if rank == 0 {
+		log.Printf("无法解析重排结果 %q，保持检索顺序", answer)
+		for i := range scores {
+			scores[i] = 1 - float32(i)/float32(len(docs))
+		}
+	}
+	return scores, nil
+}
@@ -0,0 +1,35 @@
+package rag
+
+import "testing"
+
+func TestParsePointwiseScore(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   int
+		ok     bool
+	}{
+		{"7", 7, true},
+		{"分数：10", 10, true},
+		{"0", 0, true},
+		{"<think>问题 1 和片段 2 有关，先打 3 分？</think>\n8", 8, true},
+		{"<THINK>先打 2 分</THINK>6", 6, true},
+		{"<think>没有结束的思考 5", 0, false},
+		{"8/10", 8, true},
+		{"8 / 10", 8, true},
+		{"8 out of 10", 8, true},
+		{"Score: 6 out of 10.", 6, true},
+		{"我给 8 分（满分 10 分）", 8, true},
+		{"满分 10 分，我给 4 分", 4, true},
+		{"9.5", 0, false},
+		{"得分 7.5，取整为 7", 7, true},
+		{"12", 0, false},
+		{"无法判断", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePointwiseScore(tt.answer)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parsePointwiseScore(%q) = %d, %v，期望 %d, %v", tt.answer, got, ok, tt.want, tt.ok)
+		}
+	}
+}
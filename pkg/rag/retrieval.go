@@ -0,0 +1,322 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+// 检索方式
+const (
+	RetrievalVector  = "vector"  // 只用向量检索
+	RetrievalKeyword = "keyword" // 只用 BM25 关键词检索
+	RetrievalHybrid  = "hybrid"  // 两者按倒数排名融合
+)
+
+// 检索结果中 Score 的含义，保存在元数据 score_kind 中
+const (
+	ScoreCosine = "cosine" // 向量检索的余弦相似度
+	ScoreBM25   = "bm25"   // 关键词检索的 BM25 得分
+	ScoreRRF    = "rrf"    // 混合检索的倒数排名融合得分，只反映排名，不是相似度
+	ScoreRerank = "rerank" // 重排得分，范围为 0 到 1
+)
+
+// 检索结果的元数据键：得分类型，以及分块被向量检索召回时的余弦相似度
+const (
+	scoreKindKey   = "score_kind"
+	vectorScoreKey = "vector_score"
+)
+
+// 没有分块通过得分下限时的处理方式
+const (
+	NoContextRefuse = "refuse" // 直接回答 NoContextAnswer，不调用语言模型
+	NoContextWarn   = "warn"   // 不带上下文调用语言模型，回答前加上提示
+)
+
+// NoContextAnswer 知识库中没有相关分块时的回答
+const NoContextAnswer = "知识库中没有相关信息"
+
+// 无上下文回答前的提示
+const noContextWarning = "注意：知识库中没有相关信息，以下回答没有参考文档，请自行核实。\n\n"
+
+// 日志中输出的最高得分个数
+const loggedScores = 5
+
+// RetrievalConfig 检索配置
+type RetrievalConfig struct {
+	Mode            string  // 默认检索方式，默认为 hybrid
+	TopK            int     // 返回的分块数
+	Candidates      int     // 混合检索时每路召回的候选数，不小于 TopK
+	VectorWeight    float64 // 融合时向量检索的权重
+	KeywordWeight   float64 // 融合时关键词检索的权重
+	RRFK            int     // 倒数排名融合的平滑常数，越大排名靠后的结果影响越大
+	MinScore        float64 // 向量检索的余弦相似度下限，低于该值的分块不参与融合，0 表示不限制
+	KeywordMinScore float64 // 关键词检索的 BM25 得分下限，keyword 和 hybrid 方式都生效，0 表示不限制；BM25 得分没有固定范围，应参考日志设置。为 0 时混合检索要求至少一个向量结果通过 MinScore
+	NoContext       string  // 没有分块可用时的处理方式，默认为 refuse
+}
+
+// DefaultRetrievalConfig 返回默认的检索配置
+func DefaultRetrievalConfig() RetrievalConfig {
+	return RetrievalConfig{
+		Mode:          RetrievalHybrid,
+		TopK:          5,
+		Candidates:    20,
+		VectorWeight:  1,
+		KeywordWeight: 1,
+		RRFK:          60,
+		NoContext:     NoContextRefuse,
+	}
+}
+
+// 未设置的项使用默认值，权重允许为 0
+func (c RetrievalConfig) withDefaults() RetrievalConfig {
+	def := DefaultRetrievalConfig()
+	if c.Mode == "" {
+		c.Mode = def.Mode
+	}
+	if c.TopK <= 0 {
+		c.TopK = def.TopK
+	}
+	if c.Candidates < c.TopK {
+		c.Candidates = max(def.Candidates, c.TopK)
+	}
+	if c.VectorWeight == 0 && c.KeywordWeight == 0 {
+		c.VectorWeight, c.KeywordWeight = def.VectorWeight, def.KeywordWeight
+	}
+	if c.RRFK <= 0 {
+		c.RRFK = def.RRFK
+	}
+	if c.NoContext == "" {
+		c.NoContext = def.NoContext
+	}
+	return c
+}
+
+// ValidRetrievalMode 判断检索方式是否有效
+func ValidRetrievalMode(mode string) bool {
+	switch mode {
+	case RetrievalVector, RetrievalKeyword, RetrievalHybrid:
+		return true
+	}
+	return false
+}
+
+// ValidNoContext 判断无上下文处理方式是否有效
+func ValidNoContext(action string) bool {
+	return action == NoContextRefuse || action == NoContextWarn
+}
+
+// QueryOption 单次查询的选项
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	mode   string
+	filter Filter
+}
+
+// WithRetrievalMode 指定本次查询的检索方式，覆盖配置中的默认值
+func WithRetrievalMode(mode string) QueryOption {
+	return func(o *queryOptions) {
+		o.mode = mode
+	}
+}
+
+// WithFilter 只检索元数据满足过滤条件的分块，如只查 billing 项目的表：
+//
+//	filter, _ := rag.ParseFilter("project=billing", "doc_type=table")
+//	service.Query(ctx, query, rag.WithFilter(filter))
+func WithFilter(filter Filter) QueryOption {
+	return func(o *queryOptions) {
+		o.filter = filter
+	}
+}
+
+// Retrieve 按检索方式返回与查询最相关的分块。向量检索结果先按 MinScore 过滤，
+// 关键词检索结果按 KeywordMinScore 过滤，混合检索只融合通过各自下限的结果；
+// 设置了 MinScore 而没有设置 KeywordMinScore 时，混合检索要求至少一个向量结果通过下限，否则没有结果，
+// 各路的最高得分写入日志，便于调整下限。配置了重排器时先召回更多候选，
+// 重排后按得分下限和数量截断，Score 为重排得分，原检索得分保存在元数据 retrieval_score 中。
+// Score 的类型保存在元数据 score_kind 中，分块被向量检索召回时余弦相似度另存在 vector_score 中。
+// 没有分块满足条件时返回空切片
+func (s *RAGService) Retrieve(ctx context.Context, query string, options ...QueryOption) ([]schema.Document, error) {
+	config := s.config.Retrieval.withDefaults()
+	rerank := s.config.Rerank.withDefaults()
+	if s.reranker != nil {
+		config.TopK = rerank.Candidates
+		config.Candidates = max(config.Candidates, rerank.Candidates)
+	}
+	opts := queryOptions{mode: config.Mode}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if !ValidRetrievalMode(opts.mode) {
+		return nil, fmt.Errorf("不支持的检索方式: %s", opts.mode)
+	}
+
+	// 关键词索引为空时（如跳过加载且没有持久化的索引）退回向量检索
+	mode := opts.mode
+	if mode != RetrievalVector && s.keywordIndex.Len() == 0 {
+		mode = RetrievalVector
+	}
+
+	var points []ScoredPoint
+	kind := ScoreCosine
+	vectorScores := make(map[string]float32)
+	switch mode {
+	case RetrievalVector:
+		results, err := s.vectorStore.Search(ctx, query, config.TopK, opts.filter)
+		if err != nil {
+			return nil, err
+		}
+		logTopScores("向量检索", results, config.MinScore)
+		points = aboveMinScore(results, config.MinScore)
+		for _, p := range points {
+			vectorScores[p.ID] = p.Score
+		}
+	case RetrievalKeyword:
+		kind = ScoreBM25
+		results := s.keywordIndex.Search(query, config.TopK, opts.filter)
+		logTopScores("关键词检索", results, config.KeywordMinScore)
+		points = aboveMinScore(results, config.KeywordMinScore)
+	case RetrievalHybrid:
+		vectorResults, err := s.vectorStore.Search(ctx, query, config.Candidates, opts.filter)
+		if err != nil {
+			return nil, err
+		}
+		logTopScores("向量检索", vectorResults, config.MinScore)
+		vectorResults = aboveMinScore(vectorResults, config.MinScore)
+		for _, p := range vectorResults {
+			vectorScores[p.ID] = p.Score
+		}
+		keywordResults := s.keywordIndex.Search(query, config.Candidates, opts.filter)
+		logTopScores("关键词检索", keywordResults, config.KeywordMinScore)
+		keywordResults = aboveMinScore(keywordResults, config.KeywordMinScore)
+		// 关键词检索只要求命中一个查询词，没有向量结果通过下限时说明查询与知识库无关，
+		// 不再单凭关键词命中提供上下文；设置了 KeywordMinScore 时以该下限为准
+		if config.MinScore > 0 && config.KeywordMinScore <= 0 && len(vectorResults) == 0 && len(keywordResults) > 0 {
+			log.Printf("没有向量检索结果通过得分下限，忽略 %d 个关键词检索结果", len(keywordResults))
+			keywordResults = nil
+		}
+		kind = ScoreRRF
+		points = fuseRRF(config.RRFK, config.TopK,
+			rankedList{weight: config.VectorWeight, points: vectorResults},
+			rankedList{weight: config.KeywordWeight, points: keywordResults},
+		)
+	}
+
+	docs := make([]schema.Document, len(points))
+	for i, p := range points {
+		// 复制元数据，不修改后端或关键词索引中保存的内容
+		metadata := make(map[string]interface{}, len(p.Metadata)+2)
+		for key, value := range p.Metadata {
+			metadata[key] = value
+		}
+		metadata[scoreKindKey] = kind
+		if score, ok := vectorScores[p.ID]; ok {
+			metadata[vectorScoreKey] = score
+		}
+		docs[i] = schema.Document{PageContent: p.Content, Metadata: metadata, Score: p.Score}
+	}
+	if s.reranker == nil || len(docs) == 0 {
+		return docs, nil
+	}
+
+	reranked, err := s.reranker.Rerank(ctx, query, docs)
+	if err != nil {
+		return nil, err
+	}
+	rerankedPoints := make([]ScoredPoint, len(reranked))
+	for i, doc := range reranked {
+		rerankedPoints[i] = ScoredPoint{Point: Point{Metadata: doc.Metadata}, Score: doc.Score}
+	}
+	logTopScores("重排", rerankedPoints, rerank.MinScore)
+	kept := reranked[:0]
+	for _, doc := range reranked {
+		if float64(doc.Score) < rerank.MinScore || len(kept) == rerank.TopK {
+			break
+		}
+		doc.Metadata[scoreKindKey] = ScoreRerank
+		kept = append(kept, doc)
+	}
+	return kept, nil
+}
+
+// 丢弃得分低于下限的结果，结果已按得分从高到低排列
+func aboveMinScore(points []ScoredPoint, minScore float64) []ScoredPoint {
+	for i, p := range points {
+		if float64(p.Score) < minScore {
+			return points[:i]
+		}
+	}
+	return points
+}
+
+// 把最高的几个得分和对应的条目写入日志
+func logTopScores(stage string, points []ScoredPoint, minScore float64) {
+	if len(points) == 0 {
+		log.Printf("%s没有结果", stage)
+		return
+	}
+	var parts []string
+	for _, p := range points[:min(len(points), loggedScores)] {
+		parts = append(parts, fmt.Sprintf("%.3f %s", p.Score, pointLabel(p.Metadata)))
+	}
+	if minScore > 0 {
+		log.Printf("%s最高得分（下限 %.3f）: %s", stage, minScore, strings.Join(parts, "，"))
+	} else {
+		log.Printf("%s最高得分: %s", stage, strings.Join(parts, "，"))
+	}
+}
+
+// 分块在日志中的名称，优先使用条目名称，否则使用文件名
+func pointLabel(metadata map[string]interface{}) string {
+	if name, ok := metadata[MetaName].(string); ok && name != "" {
+		return name
+	}
+	source, _ := metadata[MetaSource].(string)
+	return filepath.Base(source)
+}
+
+type rankedList struct {
+	weight float64
+	points []ScoredPoint
+}
+
+// 倒数排名融合：每个结果的得分为各路 weight / (k + 排名) 之和，排名从 1 开始。
+// 只依赖排名，不需要把余弦相似度和 BM25 得分换算到同一尺度
+func fuseRRF(k, topK int, lists ...rankedList) []ScoredPoint {
+	scores := make(map[string]float64)
+	points := make(map[string]ScoredPoint)
+	for _, list := range lists {
+		if list.weight <= 0 {
+			continue
+		}
+		for rank, p := range list.points {
+			scores[p.ID] += list.weight / float64(k+rank+1)
+			if _, ok := points[p.ID]; !ok {
+				points[p.ID] = p
+			}
+		}
+	}
+
+	fused := make([]ScoredPoint, 0, len(points))
+	for id, p := range points {
+		p.Score = float32(scores[id])
+		fused = append(fused, p)
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		if fused[i].Score != fused[j].Score {
+			return fused[i].Score > fused[j].Score
+		}
+		return fused[i].ID < fused[j].ID
+	})
+	if len(fused) > topK {
+		fused = fused[:topK]
+	}
+	return fused
+}
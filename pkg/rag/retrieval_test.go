@@ -0,0 +1,103 @@
+package rag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+// 测试用嵌入：按文本查表返回固定向量，未登记的文本返回与所有分块正交的向量
+type tableEmbedder map[string][]float32
+
+func (e tableEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = e.EmbedQuery(ctx, text)
+	}
+	return vectors, nil
+}
+
+func (e tableEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	if v, ok := e[text]; ok {
+		return v, nil
+	}
+	return []float32{0, 0, 1}, nil
+}
+
+// 两个分块分别落在第 0 维和第 1 维，同时写入向量存储和关键词索引
+func newTestService(t *testing.T, retrieval RetrievalConfig) *RAGService {
+	t.Helper()
+	ctx := context.Background()
+	embedder := tableEmbedder{
+		"MailStatus 邮件状态":  {1, 0, 0},
+		"OrderStatus 订单状态": {0, 1, 0},
+		"MailStatus 有哪些取值": {0.9, 0.1, 0},
+	}
+	config := VectorStoreConfig{Type: VectorStoreLocal, CollectionName: "test", PersistDir: t.TempDir()}
+	vectorStore, err := NewVectorStore(embedder, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { vectorStore.Backend().Close() })
+	if err := vectorStore.Backend().CreateCollection(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	keywordIndex := NewBM25Index()
+	var points []Point
+	for i, text := range []string{"MailStatus 邮件状态", "OrderStatus 订单状态"} {
+		id := chunkID("knowledge.md", i, schema.Document{PageContent: text})
+		metadata := map[string]interface{}{MetaSource: "knowledge.md"}
+		points = append(points, Point{ID: id, Vector: embedder[text], Content: text, Metadata: metadata})
+		keywordIndex.Add(id, text, metadata)
+	}
+	if err := vectorStore.Backend().Upsert(ctx, points); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &RAGService{
+		config:       RAGConfig{VectorStore: config, Retrieval: retrieval},
+		vectorStore:  vectorStore,
+		keywordIndex: keywordIndex,
+	}
+	s.SetDocsProcessed(true)
+	return s
+}
+
+func TestRetrieveHybridMinScore(t *testing.T) {
+	tests := []struct {
+		name            string
+		query           string
+		keywordMinScore float64
+		wantDocs        bool
+	}{
+		{"相关问题", "MailStatus 有哪些取值", 0, true},
+		{"只有关键词命中的无关问题", "MailStatus 今天天气怎么样", 0, false},
+		{"设置关键词下限后以该下限为准", "MailStatus 今天天气怎么样", 0.01, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, RetrievalConfig{Mode: RetrievalHybrid, MinScore: 0.5, KeywordMinScore: tt.keywordMinScore})
+			docs, err := s.Retrieve(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(docs) > 0; got != tt.wantDocs {
+				t.Fatalf("返回 %d 个分块，期望有结果: %v", len(docs), tt.wantDocs)
+			}
+		})
+	}
+}
+
+// 没有分块通过下限时直接回答 NoContextAnswer，不调用语言模型（测试服务没有配置语言模型）
+func TestQueryNoContextRefuse(t *testing.T) {
+	s := newTestService(t, RetrievalConfig{Mode: RetrievalHybrid, MinScore: 0.5, NoContext: NoContextRefuse})
+	answer, err := s.Query(context.Background(), "MailStatus 今天天气怎么样")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer.Text != NoContextAnswer || len(answer.Sources) != 0 || answer.Model != "" {
+		t.Fatalf("回答 = %+v，期望直接返回 %q", answer, NoContextAnswer)
+	}
+}
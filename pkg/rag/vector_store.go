@@ -1,44 +1,64 @@
 package rag
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
-	"net/url"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/schema"
 	"github.com/tmc/langchaingo/vectorstores"
-	"github.com/tmc/langchaingo/vectorstores/qdrant"
+)
+
+// 每批生成向量并写入的分块数
+const upsertBatchSize = 64
+
+// 向量存储类型
+const (
+	VectorStoreQdrant   = "qdrant"   // Qdrant 服务
+	VectorStoreChroma   = "chroma"   // Chroma 服务
+	VectorStorePGVector = "pgvector" // PostgreSQL 的 pgvector 扩展
+	VectorStoreLocal    = "local"    // 进程内存储，持久化到 PersistDir
 )
 
 // VectorStoreConfig 向量存储配置
 type VectorStoreConfig struct {
-	QdrantURL      string // Qdrant服务器地址
-	CollectionName string // 集合名称
-	PersistDir     string // 持久化目录
+	Type           string     // 向量存储类型，默认为 qdrant
+	URL            string     // Qdrant 或 Chroma 服务地址
+	DSN            string     // pgvector 的数据库连接串
+	Driver         string     // pgvector 使用的 database/sql 驱动名，默认为 pgx
+	CollectionName string     // 集合名称
+	PersistDir     string     // 持久化目录
+	Index          string     // 本地存储的检索方式：flat 或 hnsw
+	HNSW           HNSWConfig // 本地存储使用 HNSW 时的索引参数
 }
 
 // DefaultVectorStoreConfig 返回默认配置
 func DefaultVectorStoreConfig() VectorStoreConfig {
 	return VectorStoreConfig{
-		QdrantURL:      "http://localhost:6333",
+		Type:           VectorStoreQdrant,
+		URL:            "http://localhost:6333",
 		CollectionName: "default_collection",
 		PersistDir:     "./data/qdrant", // 默认持久化目录
 	}
 }
 
-// VectorStore 负责文档向量化和检索
+// VectorStore 负责文档向量化和检索，向量的保存和检索由 Backend 完成
 type VectorStore struct {
-	embedder    embeddings.Embedder
-	vectorStore vectorstores.VectorStore
-	config      VectorStoreConfig
+	embedder embeddings.Embedder
+	backend  Backend
+	config   VectorStoreConfig
+
+	mu      sync.Mutex
+	created bool // 集合是否已创建
+	dim     int  // 创建集合时的向量维度
 }
 
+var _ vectorstores.VectorStore = (*VectorStore)(nil)
+
 // NewVectorStore 创建一个新的向量存储
 func NewVectorStore(embedder embeddings.Embedder, config VectorStoreConfig) (*VectorStore, error) {
 	// 验证配置
@@ -50,150 +70,222 @@ func NewVectorStore(embedder embeddings.Embedder, config VectorStoreConfig) (*Ve
 	if config.CollectionName == "" {
 		config.CollectionName = "default_collection"
 	}
+	if config.Type == "" {
+		config.Type = VectorStoreQdrant
+	}
+
+	start := time.Now()
+	backend, err := NewBackend(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建向量存储失败: %w", err)
+	}
+	vs := &VectorStore{
+		embedder: embedder,
+		backend:  backend,
+		config:   config,
+	}
 
-	// 确保有Qdrant URL
-	if config.QdrantURL == "" {
-		config.QdrantURL = "http://localhost:6333"
+	// 本地存储不依赖外部服务，集合在首次写入时按向量维度创建
+	if config.Type == VectorStoreLocal {
+		count, _ := backend.Count(context.Background())
+		log.Printf("已加载本地向量存储，共 %d 个分块，耗时 %s", count, time.Since(start).Round(time.Millisecond))
+		return vs, nil
 	}
 
-	log.Printf("开始初始化向量存储，配置: %+v", config)
+	log.Printf("开始初始化向量存储，类型: %s，集合: %s", config.Type, config.CollectionName)
 
 	// 测试嵌入模型
 	log.Printf("正在测试嵌入模型...")
 	testEmbed, err := embedder.EmbedQuery(context.Background(), "test")
 	if err != nil {
+		backend.Close()
 		return nil, fmt.Errorf("嵌入模型测试失败: %w", err)
 	}
 	log.Printf("嵌入模型测试成功，向量维度: %d", len(testEmbed))
 
-	// 解析URL
-	qdrantURL, err := url.Parse(config.QdrantURL)
-	if err != nil {
-		return nil, fmt.Errorf("解析Qdrant URL失败: %w", err)
-	}
-
-	// 直接使用 REST API 创建集合
-	err = createQdrantCollection(config.QdrantURL, config.CollectionName, len(testEmbed))
-	if err != nil {
-		log.Printf("使用REST API创建集合时出错: %v", err)
-		// 这里我们不返回错误，因为集合可能已经存在
+	if err := vs.ensureCollection(context.Background(), len(testEmbed)); err != nil {
+		backend.Close()
+		return nil, err
 	}
 
-	// 创建向量存储
-	log.Printf("正在创建向量存储")
+	log.Printf("成功创建向量存储，集合名称: %s", config.CollectionName)
+	return vs, nil
+}
 
-	// 准备Qdrant选项
-	options := []qdrant.Option{
-		qdrant.WithEmbedder(embedder),
-		qdrant.WithCollectionName(config.CollectionName),
-		qdrant.WithURL(*qdrantURL),
+// 按向量维度创建集合，只在第一次调用时执行
+func (vs *VectorStore) ensureCollection(ctx context.Context, dim int) error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	if vs.created {
+		return nil
 	}
-
-	// 创建向量存储
-	store, err := qdrant.New(options...)
-	if err != nil {
-		log.Printf("创建向量存储时出错: %v", err)
-		return nil, fmt.Errorf("创建向量存储失败: %w", err)
+	if err := vs.backend.CreateCollection(ctx, dim); err != nil {
+		return fmt.Errorf("创建集合 %s 失败: %w", vs.config.CollectionName, err)
 	}
+	vs.created, vs.dim = true, dim
+	return nil
+}
 
-	log.Printf("成功创建向量存储，集合名称: %s", config.CollectionName)
+// Reset 删除集合中的所有点：删除集合后按原来的向量维度重新创建
+func (vs *VectorStore) Reset(ctx context.Context) error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	if err := vs.backend.Drop(ctx); err != nil {
+		return err
+	}
+	vs.created = false
+	if vs.dim == 0 {
+		return nil
+	}
+	if err := vs.backend.CreateCollection(ctx, vs.dim); err != nil {
+		return fmt.Errorf("创建集合 %s 失败: %w", vs.config.CollectionName, err)
+	}
+	vs.created = true
+	return nil
+}
 
-	return &VectorStore{
-		embedder:    embedder,
-		vectorStore: store,
-		config:      config,
-	}, nil
-}
-
-// createQdrantCollection 使用 REST API 创建 Qdrant 集合
-func createQdrantCollection(baseURL, collectionName string, dimensions int) error {
-	// 构建请求URL
-	createURL := fmt.Sprintf("%s/collections/%s", baseURL, collectionName)
-
-	// 构建请求体
-	requestBody := map[string]interface{}{
-		"vectors": map[string]interface{}{
-			"size":     dimensions,
-			"distance": "Cosine",
-		},
-		"optimizers_config": map[string]interface{}{
-			"default_segment_number": 2,
-		},
-		"replication_factor":       1,
-		"write_consistency_factor": 1,
-	}
-
-	// 转换为JSON
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("序列化请求体失败: %w", err)
+// AddDocuments 实现 vectorstores.VectorStore，使用随机 ID 写入文档并持久化
+func (vs *VectorStore) AddDocuments(ctx context.Context, docs []schema.Document, _ ...vectorstores.Option) ([]string, error) {
+	if len(docs) == 0 {
+		log.Printf("没有文档需要添加")
+		return nil, nil
 	}
 
-	// 创建HTTP请求
-	req, err := http.NewRequest("PUT", createURL, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return fmt.Errorf("创建HTTP请求失败: %w", err)
+	log.Printf("正在添加 %d 个文档", len(docs))
+	ids := make([]string, len(docs))
+	for i := range ids {
+		ids[i] = uuid.NewString()
+	}
+	if err := vs.UpsertDocuments(ctx, ids, docs); err != nil {
+		return nil, fmt.Errorf("添加文档失败: %w", err)
+	}
+	if err := vs.Flush(ctx); err != nil {
+		return nil, err
 	}
 
-	// 设置请求头
-	req.Header.Set("Content-Type", "application/json")
+	log.Printf("成功添加所有文档")
+	return ids, nil
+}
 
-	// 发送请求
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("发送HTTP请求失败: %w", err)
+// UpsertDocuments 以指定的 ID 写入文档，ID 已存在的点会被覆盖
+func (vs *VectorStore) UpsertDocuments(ctx context.Context, ids []string, docs []schema.Document) error {
+	if len(ids) != len(docs) {
+		return fmt.Errorf("ID 数量 %d 与文档数量 %d 不一致", len(ids), len(docs))
 	}
-	defer resp.Body.Close()
 
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusConflict {
-		// 读取响应体
-		var responseBody map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
-			return fmt.Errorf("解析响应体失败: %w", err)
+	for start := 0; start < len(docs); start += upsertBatchSize {
+		end := min(start+upsertBatchSize, len(docs))
+
+		texts := make([]string, 0, end-start)
+		for _, doc := range docs[start:end] {
+			texts = append(texts, doc.PageContent)
+		}
+		vectors, err := vs.embedder.EmbedDocuments(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("生成向量失败: %w", err)
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("生成的向量数量 %d 与文档数量 %d 不一致", len(vectors), len(texts))
+		}
+		if err := vs.ensureCollection(ctx, len(vectors[0])); err != nil {
+			return err
 		}
-		return fmt.Errorf("创建集合失败，状态码: %d，响应: %v", resp.StatusCode, responseBody)
-	}
 
-	log.Printf("成功创建或确认集合 %s 存在", collectionName)
+		points := make([]Point, len(texts))
+		for i, doc := range docs[start:end] {
+			points[i] = Point{ID: ids[start+i], Vector: vectors[i], Content: doc.PageContent, Metadata: doc.Metadata}
+		}
+		if err := vs.backend.Upsert(ctx, points); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// AddDocuments 添加文档到向量存储
-func (vs *VectorStore) AddDocuments(ctx context.Context, docs []schema.Document) error {
-	if len(docs) == 0 {
-		log.Printf("没有文档需要添加")
-		return nil
+// DeleteBySource 删除来源为 source 的所有点，keep 中的 ID 除外
+func (vs *VectorStore) DeleteBySource(ctx context.Context, source string, keep []string) error {
+	filter := Filter{Match: map[string]interface{}{MetaSource: source}, ExcludeIDs: keep}
+	if err := vs.backend.Delete(ctx, filter); err != nil {
+		return fmt.Errorf("删除 %s 的向量失败: %w", source, err)
 	}
+	return nil
+}
 
-	log.Printf("正在添加 %d 个文档", len(docs))
-
-	// 直接添加文档
-	_, err := vs.vectorStore.AddDocuments(ctx, docs)
-	if err != nil {
-		return fmt.Errorf("添加文档失败: %w", err)
-	}
+// Flush 持久化尚未落盘的写入
+func (vs *VectorStore) Flush(ctx context.Context) error {
+	return vs.backend.Flush(ctx)
+}
 
-	log.Printf("成功添加所有文档")
-	return nil
+// Close 持久化尚未落盘的写入并释放后端资源
+func (vs *VectorStore) Close() error {
+	return vs.backend.Close()
 }
 
-// SimilaritySearch 执行相似性搜索
-func (vs *VectorStore) SimilaritySearch(ctx context.Context, query string, k int) ([]schema.Document, error) {
+// SimilaritySearch 实现 vectorstores.VectorStore，执行相似性搜索。
+// 支持 vectorstores.WithScoreThreshold、WithEmbedder，
+// 过滤条件可以是 Filter 或 map[string]any 形式的元数据等值条件
+func (vs *VectorStore) SimilaritySearch(ctx context.Context, query string, k int, options ...vectorstores.Option) ([]schema.Document, error) {
 	log.Printf("正在执行相似性搜索，查询: %s", query)
 
-	// 执行搜索
-	docs, err := vs.vectorStore.SimilaritySearch(ctx, query, k)
+	opts := vectorstores.Options{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	var filter Filter
+	switch f := opts.Filters.(type) {
+	case nil:
+	case Filter:
+		filter = f
+	case map[string]interface{}:
+		filter = Filter{Match: f}
+	default:
+		return nil, fmt.Errorf("不支持的过滤条件类型: %T", opts.Filters)
+	}
+
+	embedder := vs.embedder
+	if opts.Embedder != nil {
+		embedder = opts.Embedder
+	}
+	points, err := vs.search(ctx, embedder, query, k, filter)
 	if err != nil {
-		return nil, fmt.Errorf("相似性搜索失败: %w", err)
+		return nil, err
+	}
+
+	docs := make([]schema.Document, 0, len(points))
+	for _, p := range points {
+		if p.Score < opts.ScoreThreshold {
+			continue
+		}
+		docs = append(docs, schema.Document{PageContent: p.Content, Metadata: p.Metadata, Score: p.Score})
 	}
 
 	log.Printf("搜索完成，找到 %d 个相关文档", len(docs))
 	return docs, nil
 }
 
-// GetVectorStore 获取底层向量存储
+// Search 返回与查询最相近的 k 个点，结果带有点 ID，得分为余弦相似度
+func (vs *VectorStore) Search(ctx context.Context, query string, k int, filter Filter) ([]ScoredPoint, error) {
+	return vs.search(ctx, vs.embedder, query, k, filter)
+}
+
+func (vs *VectorStore) search(ctx context.Context, embedder embeddings.Embedder, query string, k int, filter Filter) ([]ScoredPoint, error) {
+	vector, err := embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("生成查询向量失败: %w", err)
+	}
+	points, err := vs.backend.Search(ctx, vector, k, filter)
+	if err != nil {
+		return nil, fmt.Errorf("相似性搜索失败: %w", err)
+	}
+	return points, nil
+}
+
+// GetVectorStore 获取 langchaingo 形式的向量存储
 func (vs *VectorStore) GetVectorStore() vectorstores.VectorStore {
-	return vs.vectorStore
+	return vs
+}
+
+// Backend 返回底层的向量存储后端
+func (vs *VectorStore) Backend() Backend {
+	return vs.backend
 }
@@ -0,0 +1,274 @@
+// Package segment 提供基于词典的中英文混合分词，用于生成搜索标签和关键词。
+//
+// 中文部分按词典词频求最大概率切分，英文和数字按驼峰、下划线拆分并转为小写。
+// 内置词典偏向业务系统常用词，可以通过自定义词典补充领域词汇。
+package segment
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// 自定义词条未指定词频时使用的默认词频
+const defaultFreq = 1000
+
+//go:embed dict.txt
+var defaultDict string
+
+//go:embed stopwords.txt
+var defaultStopwords string
+
+var (
+	defaultOnce      sync.Once
+	defaultSegmenter *Segmenter
+)
+
+// Segmenter 分词器，加载完词典和停用词后可并发调用 Cut 和 Keywords
+type Segmenter struct {
+	freq      map[string]float64
+	total     float64
+	maxLen    int // 词典中最长词的字数
+	stopwords map[string]bool
+}
+
+// Default 返回只加载内置词典和停用词的共享分词器，不要向其添加词条
+func Default() *Segmenter {
+	defaultOnce.Do(func() {
+		defaultSegmenter = New()
+	})
+	return defaultSegmenter
+}
+
+// New 创建加载了内置词典和停用词的分词器
+func New() *Segmenter {
+	s := &Segmenter{
+		freq:      make(map[string]float64),
+		stopwords: make(map[string]bool),
+	}
+	// 内置资源格式固定，不会出错
+	s.loadDict(strings.NewReader(defaultDict))
+	s.loadStopwords(strings.NewReader(defaultStopwords))
+	return s
+}
+
+// LoadDictFile 加载自定义词典，每行一个词，可选词频：“退款单 2000”
+func (s *Segmenter) LoadDictFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开词典失败: %w", err)
+	}
+	defer f.Close()
+	if err := s.loadDict(f); err != nil {
+		return fmt.Errorf("读取词典 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// LoadStopwordsFile 加载停用词表，每行一个词
+func (s *Segmenter) LoadStopwordsFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开停用词表失败: %w", err)
+	}
+	defer f.Close()
+	if err := s.loadStopwords(f); err != nil {
+		return fmt.Errorf("读取停用词表 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// AddWord 添加词条，freq 不大于 0 时使用默认词频
+func (s *Segmenter) AddWord(word string, freq float64) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return
+	}
+	if freq <= 0 {
+		freq = defaultFreq
+	}
+	s.total += freq - s.freq[word]
+	s.freq[word] = freq
+	if n := len([]rune(word)); n > s.maxLen {
+		s.maxLen = n
+	}
+}
+
+// AddStopword 添加停用词
+func (s *Segmenter) AddStopword(word string) {
+	if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+		s.stopwords[word] = true
+	}
+}
+
+// IsStopword 判断是否为停用词
+func (s *Segmenter) IsStopword(word string) bool {
+	return s.stopwords[strings.ToLower(word)]
+}
+
+func (s *Segmenter) loadDict(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		freq := 0.0
+		if len(fields) > 1 {
+			if v, err := strconv.ParseFloat(fields[1], 64); err == nil {
+				freq = v
+			}
+		}
+		s.AddWord(fields[0], freq)
+	}
+	return scanner.Err()
+}
+
+func (s *Segmenter) loadStopwords(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		s.AddStopword(line)
+	}
+	return scanner.Err()
+}
+
+// Cut 把文本切分为词，中文按词典切分，英文按驼峰和下划线拆分并转为小写，
+// 标点和空白被丢弃
+func (s *Segmenter) Cut(text string) []string {
+	var words []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		j := i + 1
+		switch {
+		case unicode.Is(unicode.Han, r):
+			for j < len(runes) && unicode.Is(unicode.Han, runes[j]) {
+				j++
+			}
+			words = append(words, s.cutHan(runes[i:j])...)
+		case isWordRune(r):
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			for _, word := range splitIdentifier(string(runes[i:j])) {
+				words = append(words, strings.ToLower(word))
+			}
+		}
+		i = j
+	}
+	return words
+}
+
+// Keywords 返回文本中的关键词：去掉停用词、单个汉字、单个字母和纯数字，按出现顺序去重
+func (s *Segmenter) Keywords(text string) []string {
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, word := range s.Cut(text) {
+		if seen[word] || s.stopwords[word] || !isKeyword(word) {
+			continue
+		}
+		seen[word] = true
+		keywords = append(keywords, word)
+	}
+	return keywords
+}
+
+func isKeyword(word string) bool {
+	runes := []rune(word)
+	if len(runes) < 2 {
+		return false
+	}
+	for _, r := range runes {
+		if !unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// 按最大概率路径切分一段连续汉字，相邻的未登录单字合并为一个词
+func (s *Segmenter) cutHan(runes []rune) []string {
+	n := len(runes)
+	// 未登录的单字取极小的词频
+	minLog := math.Log(1 / (s.total + 1))
+	logTotal := math.Log(s.total + 1)
+
+	best := make([]float64, n+1)
+	next := make([]int, n+1)
+	for i := n - 1; i >= 0; i-- {
+		best[i] = math.Inf(-1)
+		for j := i + 1; j <= n && j-i <= s.maxLen; j++ {
+			score := minLog
+			if freq, ok := s.freq[string(runes[i:j])]; ok {
+				score = math.Log(freq) - logTotal
+			} else if j-i > 1 {
+				continue
+			}
+			if score+best[j] > best[i] {
+				best[i] = score + best[j]
+				next[i] = j
+			}
+		}
+		if next[i] == 0 {
+			best[i] = minLog + best[i+1]
+			next[i] = i + 1
+		}
+	}
+
+	var words []string
+	unknown := ""
+	for i := 0; i < n; i = next[i] {
+		word := string(runes[i:next[i]])
+		if _, ok := s.freq[word]; !ok && next[i]-i == 1 {
+			unknown += word
+			continue
+		}
+		if unknown != "" {
+			words = append(words, unknown)
+			unknown = ""
+		}
+		words = append(words, word)
+	}
+	if unknown != "" {
+		words = append(words, unknown)
+	}
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// 按下划线和驼峰拆分标识符，连续大写视为一个缩写：HTTPStatus → HTTP、Status
+func splitIdentifier(s string) []string {
+	var words []string
+	for _, part := range strings.Split(s, "_") {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			lowerToUpper := unicode.IsLower(prev) && unicode.IsUpper(cur)
+			acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}